@@ -0,0 +1,44 @@
+package main
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/calc"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/input"
+)
+
+// apr calculates the effective annual rate of the loan, historically
+// and optionally for a projected payment plan, and writes it with the
+// nominal rates as CSV to standard output.
+func apr(args []string) {
+	var (
+		plan    string // -P flag
+		horizon int    // -H flag
+		lf      input.Flags
+	)
+
+	fs := newFlagSet("apr")
+	fs.StringVar(&plan, "P", "", "payment plan CSV `file` to project the loan with")
+	fs.IntVar(&horizon, "H", 50, "largest number of `years` to project the loan")
+	lf.Register(fs)
+
+	fs.Parse(args)
+
+	in, err := lf.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	planL, err := in.ReadPlan(plan)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	last := time.Now().AddDate(horizon, 0, 0)
+
+	if err := calc.RunEffectiveRate(os.Stdout, in.FirstDay, in.Principal, in.NewBank(), planL, last, in.OutComma); err != nil {
+		log.Fatalf("failed to calculate effective annual rate: %s", err)
+	}
+}
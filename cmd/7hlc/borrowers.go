@@ -0,0 +1,58 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/calc"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/input"
+	intio "gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// borrowers calculates each borrower's part of a loan shared by
+// several borrowers and writes it as CSV to standard output.
+func borrowers(args []string) {
+	var (
+		borrowersFile string // -b flag
+		attributions  string // -a flag
+		year          int    // -y flag
+		lf            input.Flags
+	)
+
+	fs := newFlagSet("borrowers")
+	fs.StringVar(&borrowersFile, "b", "borrowers.csv", "borrowers CSV `file`")
+	fs.StringVar(&attributions, "a", "", "transaction attributions CSV `file`")
+	fs.IntVar(&year, "y", 0, "write the statement of `year` instead of the daily series")
+	lf.Register(fs)
+
+	fs.Parse(args)
+
+	in, err := lf.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	borrowersL, err := intio.ReadBorrowers(borrowersFile, in.InComma)
+	if err != nil {
+		log.Fatalf("failed to read borrowers: %s", err)
+	}
+
+	var attributionsL []intio.Attribution
+	if attributions != "" {
+		attributionsL, err = intio.ReadAttributions(attributions, in.InComma)
+		if err != nil {
+			log.Fatalf("failed to read attributions: %s", err)
+		}
+	}
+
+	if year != 0 {
+		err = calc.BorrowerStatement(os.Stdout, in.FirstDay, in.Principal, in.NewBank(),
+			borrowersL, attributionsL, year, in.OutComma)
+	} else {
+		err = calc.RunBorrowers(os.Stdout, in.FirstDay, in.Principal, in.NewBank(),
+			borrowersL, attributionsL, in.OutComma)
+	}
+	if err != nil {
+		log.Fatalf("failed to calculate borrowers' parts: %s", err)
+	}
+}
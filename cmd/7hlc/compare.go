@@ -0,0 +1,102 @@
+package main
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/calc"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/input"
+	intio "gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// compare runs two scenarios of repaying the loan over the same
+// horizon and writes them side by side as CSV to standard output. The
+// second scenario uses the inputs of the first one unless overridden,
+// and its scenario file, if any, is overlaid on top of them.
+func compare(args []string) {
+	var (
+		nameA, nameB   string // -a and -b flags
+		planA, planB   string // -P and -Q flags
+		transactionsB  string // -T flag
+		interestRatesB string // -I flag
+		scenarioB      string // -U flag
+		horizon        int    // -H flag
+		lf             input.Flags
+	)
+
+	fs := newFlagSet("compare")
+	fs.StringVar(&nameA, "a", "A", "`name` of the first scenario")
+	fs.StringVar(&nameB, "b", "B", "`name` of the second scenario")
+	fs.StringVar(&planA, "P", "", "payment plan CSV `file` of the first scenario")
+	fs.StringVar(&planB, "Q", "", "payment plan CSV `file` of the second scenario")
+	fs.StringVar(&transactionsB, "T", "", "transactions CSV `file` of the second scenario")
+	fs.StringVar(&interestRatesB, "I", "", "interest rates CSV `file` of the second scenario")
+	fs.StringVar(&scenarioB, "U", "", "scenario JSON `file` overlaid on the inputs of the second scenario")
+	fs.IntVar(&horizon, "H", 10, "`years` from today to compare the scenarios over")
+	lf.Register(fs)
+
+	fs.Parse(args)
+
+	in, err := lf.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	inB := in
+
+	if transactionsB != "" {
+		inB.Transactions, err = input.ReadTransactions(transactionsB, "", lf.KindRules, in.InComma)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	if interestRatesB != "" {
+		inB.InterestRates, err = intio.ReadInterestRates(interestRatesB, in.InComma)
+		if err != nil {
+			log.Fatalf("failed to read interest rates: %s", err)
+		}
+	}
+
+	var overlay intio.Scenario
+	if scenarioB != "" {
+		overlay, err = intio.ReadScenario(scenarioB)
+		if err != nil {
+			log.Fatalf("failed to read scenario: %s", err)
+		}
+		inB.Transactions = overlay.ApplyTransactions(inB.Transactions)
+		inB.InterestRates = overlay.ApplyInterestRates(inB.InterestRates)
+	}
+
+	a := calc.Scenario{Name: nameA, Bank: in.NewBank()}
+	b := calc.Scenario{Name: nameB, Bank: inB.NewBank()}
+
+	a.Plan, err = in.ReadPlan(planA)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	b.Plan, err = inB.ReadPlan(planB)
+	if err != nil {
+		log.Fatal(err)
+	}
+	b.Plan = overlay.ApplyPlan(b.Plan)
+
+	last := time.Now().AddDate(horizon, 0, 0)
+
+	c, err := calc.RunCompare(os.Stdout, in.FirstDay, in.Principal, a, b, last, in.OutComma)
+	if err != nil {
+		log.Fatalf("failed to compare scenarios: %s", err)
+	}
+
+	names := []string{nameA, nameB}
+	log.Printf("Cost of %s: %s, cost of %s: %s.",
+		nameA, c.Costs[0].FloatString(2), nameB, c.Costs[1].FloatString(2))
+	if c.Cheaper < 0 {
+		log.Print("The scenarios cost the same.")
+	} else {
+		log.Printf("%s is cheaper from %s on.", names[c.Cheaper], c.BreakEven.Format(internal.DateLayout))
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/calc"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/input"
+)
+
+// cost calculates the total cost of the loan, per year and in total,
+// and writes it as CSV to standard output.
+func cost(args []string) {
+	var lf input.Flags
+
+	fs := newFlagSet("cost")
+	lf.Register(fs)
+
+	fs.Parse(args)
+
+	in, err := lf.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := calc.RunCost(os.Stdout, in.FirstDay, in.Principal, in.NewBank(), in.OutComma); err != nil {
+		log.Fatalf("failed to calculate cost: %s", err)
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"log"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/input"
+	intio "gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// importStatements imports the transactions of the CSV files given as
+// arguments into a ledger, leaving out those already in it.
+func importStatements(args []string) {
+	var (
+		ledger     string // -l flag
+		csvInComma string // -n flag
+	)
+
+	fs := newFlagSet("import")
+	fs.StringVar(&ledger, "l", "ledger.jsonl", "ledger `file` to import the transactions into")
+	fs.StringVar(&csvInComma, "n", ";", "input CSV file field delimiter `character` ")
+
+	fs.Parse(args)
+
+	inComma, err := input.ParseComma(csvInComma)
+	if err != nil {
+		log.Fatalf("failed to get input CSV file field delimiter character: %s", err)
+	}
+
+	if fs.NArg() == 0 {
+		log.Fatal("no transactions CSV files to import")
+	}
+
+	now := time.Now()
+
+	for _, file := range fs.Args() {
+		transactions, err := intio.ReadTransactions(file, inComma)
+		if err != nil {
+			log.Fatalf("failed to read transactions from %s: %s", file, err)
+		}
+
+		added, err := intio.Import(ledger, file, transactions, now)
+		if err != nil {
+			log.Fatalf("failed to import transactions from %s: %s", file, err)
+		}
+
+		log.Printf("Imported %d of %d transaction(s) from %s; the rest were already in the ledger.",
+			len(added), len(transactions), file)
+	}
+}
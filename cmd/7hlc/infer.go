@@ -0,0 +1,48 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/calc"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/input"
+	intio "gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// infer infers the annual interest rates from reported balances and
+// writes them as an interest rates CSV file to standard output.
+func infer(args []string) {
+	var (
+		checkpoints string // -c flag
+		lf          = input.Flags{WithoutRates: true}
+	)
+
+	fs := newFlagSet("infer")
+	fs.StringVar(&checkpoints, "c", "checkpoints.csv", "reported balances (checkpoints) CSV `file`")
+	lf.Register(fs)
+
+	fs.Parse(args)
+
+	in, err := lf.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	checkpointsL, err := intio.ReadCheckpoints(checkpoints, in.InComma)
+	if err != nil {
+		log.Fatalf("failed to read checkpoints: %s", err)
+	}
+
+	log.Printf("Inferring interest rates based on %d transaction(s) and %d checkpoint(s).",
+		len(in.Transactions), len(checkpointsL))
+
+	bank := in.NewBank()
+	rates, err := calc.InferRates(bank, calc.NewLoan(in.Principal), in.FirstDay, checkpointsL)
+	if err != nil {
+		log.Fatalf("failed to infer interest rates: %s", err)
+	}
+
+	if err := intio.WriteInterestRates(os.Stdout, rates, in.OutComma); err != nil {
+		log.Fatalf("failed to write interest rates: %s", err)
+	}
+}
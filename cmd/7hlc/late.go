@@ -0,0 +1,33 @@
+package main
+
+import (
+	"log"
+	"os"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/calc"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/input"
+)
+
+// late checks the payments made against the minimum payments and
+// writes the outcome of each due day as CSV to standard output.
+func late(args []string) {
+	var lf input.Flags
+
+	fs := newFlagSet("late")
+	lf.Register(fs)
+
+	fs.Parse(args)
+
+	if lf.MinimumPayments == "" {
+		log.Fatal("minimum payments (-M) are required")
+	}
+
+	in, err := lf.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := calc.RunDues(os.Stdout, in.FirstDay, in.Principal, in.NewBank(), in.OutComma); err != nil {
+		log.Fatalf("failed to check payments: %s", err)
+	}
+}
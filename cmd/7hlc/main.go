@@ -1,88 +1,117 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"flag"
+	"fmt"
 	"log"
-	"math/big"
 	"os"
-	"time"
+	"os/signal"
 
-	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
 	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/buildinfo"
 	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/calc"
-	intio "gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/input"
 )
 
-var (
-	version       bool   // -v flag
-	transactions  string // -t flag
-	interestRates string // -r flag
-	firstDay      string // -d flag
-	principal     string // -p flag
-	csvInComma    string // -n flag
-	csvOutComma   string // -u flag
-)
-
-func checkCSVComma(csvComma string) (rune, error) {
-	comma := []rune(csvComma)
-	if len := len(comma); len != 1 {
-		return rune(0), errors.New("must be a single character")
-	} else {
-		return comma[0], nil
-	}
+// commands maps the name of each subcommand to its implementation.
+// Running 7hlc without a known subcommand runs the loan calculation,
+// like the run subcommand.
+var commands = map[string]func(args []string){
+	"apr":       apr,
+	"borrowers": borrowers,
+	"compare":   compare,
+	"cost":      cost,
+	"import":    importStatements,
+	"infer":     infer,
+	"late":      late,
+	"parts":     parts,
+	"penalty":   penalty,
+	"reconcile": reconcile,
+	"run":       run,
+	"serve":     serve,
+	"simulate":  simulate,
+	"stress":    stress,
+	"tui":       explore,
 }
 
 func main() {
 	log.SetFlags(0)
 
-	flag.BoolVar(&version, "v", false, "print the version")
-	flag.StringVar(&transactions, "t", "transactions.csv", "transactions CSV `file`")
-	flag.StringVar(&interestRates, "r", "interest_rates.csv", "interest rates CSV `file`")
-	flag.StringVar(&firstDay, "d", "2022-06-27", "`date` of first day of loan")
-	flag.StringVar(&principal, "p", "200000", "principal `balance` on first day")
-	flag.StringVar(&csvInComma, "n", ";", "input CSV file field delimiter `character` ")
-	flag.StringVar(&csvOutComma, "u", ";", "output CSV file field delimiter `character` ")
+	if len(os.Args) > 1 {
+		if cmd, ok := commands[os.Args[1]]; ok {
+			cmd(os.Args[2:])
+			return
+		}
+	}
+
+	run(os.Args[1:])
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	if name != "" {
+		name = " " + name
+	}
+	return flag.NewFlagSet(fmt.Sprintf("%s%s", os.Args[0], name), flag.ExitOnError)
+}
+
+func run(args []string) {
+	var (
+		version  bool   // -v flag
+		snapshot string // -s flag
+		lf       input.Flags
+	)
+
+	fs := newFlagSet("")
+	fs.BoolVar(&version, "v", false, "print the version")
+	fs.StringVar(&snapshot, "s", "", "JSON `file` with a snapshot of the loan to resume from, if it exists, and to update; a resumed run writes only the days after the snapshot, under a new header")
+	lf.Register(fs)
 
-	flag.Parse()
+	fs.Parse(args)
 
 	if version {
 		log.Print(buildinfo.Version())
 		return
 	}
 
-	inComma, err := checkCSVComma(csvInComma)
+	in, err := lf.Load()
 	if err != nil {
-		log.Fatalf("failed to get input CSV file field delimiter character: %s", err)
+		log.Fatal(err)
 	}
 
-	outComma, err := checkCSVComma(csvOutComma)
-	if err != nil {
-		log.Fatalf("failed to get output CSV file field delimiter character: %s", err)
-	}
+	log.Printf("Calculating loan based on %d transaction(s) and %d interest rate entries.",
+		len(in.Transactions), len(in.InterestRates))
 
-	firstDayT, err := time.Parse(internal.DateLayout, firstDay)
-	if err != nil {
-		log.Fatalf("failed to read first day argument: %s", err)
-	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
 
-	principalBalance, ok := new(big.Rat).SetString(principal)
-	if !ok {
-		log.Fatalf("failed to parse principal balance %q", principal)
+	if snapshot == "" {
+		if err := calc.Run(ctx, os.Stdout, in.FirstDay, in.Principal, in.NewBank(), in.OutComma); err != nil {
+			log.Fatalf("failed to calculate loan: %s", err)
+		}
+		return
 	}
 
-	transactionsL, err := intio.ReadTransactions(transactions, inComma)
-	if err != nil {
-		log.Fatalf("failed to read transactions: %s", err)
+	var from *calc.Snapshot
+	if s, err := calc.ReadSnapshot(snapshot); err == nil {
+		from = &s
+	} else if !errors.Is(err, os.ErrNotExist) {
+		log.Fatalf("failed to read snapshot: %s", err)
 	}
 
-	interestRatesL, err := intio.ReadInterestRates(interestRates, inComma)
+	bank := in.NewBank()
+	taken, err := calc.RunFrom(ctx, os.Stdout, in.FirstDay, in.Principal, bank, from, in.OutComma)
+	if errors.Is(err, calc.ErrStaleSnapshot) {
+		log.Printf("Recalculating from the first day: %s.", err)
+		taken, err = calc.RunFrom(ctx, os.Stdout, in.FirstDay, in.Principal, bank, nil, in.OutComma)
+	}
 	if err != nil {
-		log.Fatalf("failed to read interest rates: %s", err)
+		log.Fatalf("failed to calculate loan: %s", err)
 	}
 
-	log.Printf("Calculating loan based on %d transaction(s) and %d interest rate entries.",
-		len(transactionsL), len(interestRatesL))
-
-	calc.Run(os.Stdout, firstDayT, principalBalance, interestRatesL, transactionsL, outComma)
+	if !taken.Day.IsZero() {
+		if err := calc.WriteSnapshot(snapshot, taken); err != nil {
+			log.Fatalf("failed to save snapshot: %s", err)
+		}
+	}
 }
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/calc"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/input"
+	intio "gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// parts calculates a loan split into several parts and writes the
+// state of each part and the totals as CSV to standard output.
+func parts(args []string) {
+	var (
+		loanParts    string // -C flag
+		transactions string // -t flag
+		ledger       string // -l flag
+		kindRules    string // -k flag
+		firstDay     string // -d flag
+		csvInComma   string // -n flag
+		csvOutComma  string // -u flag
+	)
+
+	fs := newFlagSet("parts")
+	fs.StringVar(&loanParts, "C", "loan_parts.csv", "loan parts CSV `file`")
+	fs.StringVar(&transactions, "t", "transactions.csv", "transactions CSV `file`")
+	fs.StringVar(&ledger, "l", "", "ledger `file` to read the transactions from instead of -t (see the import command)")
+	fs.StringVar(&kindRules, "k", "", "transaction kind rules CSV `file` applied before the default rules")
+	fs.StringVar(&firstDay, "d", "2022-06-27", "`date` of first day of loan")
+	fs.StringVar(&csvInComma, "n", ";", "input CSV file field delimiter `character` ")
+	fs.StringVar(&csvOutComma, "u", ";", "output CSV file field delimiter `character` ")
+
+	fs.Parse(args)
+
+	inComma, err := input.ParseComma(csvInComma)
+	if err != nil {
+		log.Fatalf("failed to get input CSV file field delimiter character: %s", err)
+	}
+
+	outComma, err := input.ParseComma(csvOutComma)
+	if err != nil {
+		log.Fatalf("failed to get output CSV file field delimiter character: %s", err)
+	}
+
+	firstDayT, err := time.Parse(internal.DateLayout, firstDay)
+	if err != nil {
+		log.Fatalf("failed to read first day argument: %s", err)
+	}
+
+	transactionsL, err := input.ReadTransactions(transactions, ledger, kindRules, inComma)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	loanPartsL, err := intio.ReadLoanParts(loanParts, inComma)
+	if err != nil {
+		log.Fatalf("failed to read loan parts: %s", err)
+	}
+
+	partsL := make([]calc.Part, len(loanPartsL))
+	for i, lp := range loanPartsL {
+		p := calc.Part{Name: lp.Name, Principal: lp.Principal, Share: lp.Share}
+
+		p.InterestRates, err = intio.ReadInterestRates(lp.InterestRates, inComma)
+		if err != nil {
+			log.Fatalf("failed to read interest rates of loan part %q: %s", lp.Name, err)
+		}
+
+		if lp.FixedRatePeriods != "" {
+			p.FixedRatePeriods, err = intio.ReadFixedRatePeriods(lp.FixedRatePeriods, inComma)
+			if err != nil {
+				log.Fatalf("failed to read fixed-rate periods of loan part %q: %s", lp.Name, err)
+			}
+		}
+
+		partsL[i] = p
+	}
+
+	log.Printf("Calculating %d loan part(s) based on %d transaction(s).", len(partsL), len(transactionsL))
+
+	if err := calc.RunParts(os.Stdout, firstDayT, partsL, transactionsL, outComma); err != nil {
+		log.Fatalf("failed to calculate loan parts: %s", err)
+	}
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/csv"
+	"log"
+	"math/big"
+	"os"
+	"strconv"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/input"
+	intio "gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// penalty calculates the prepayment penalty for an extra amortization
+// during a fixed-rate period and writes it as CSV to standard output.
+func penalty(args []string) {
+	var (
+		comparisonRates string // -c flag
+		amount          string // -a flag
+		when            string // -w flag
+		addition        string // -i flag
+		lf              input.Flags
+	)
+
+	fs := newFlagSet("penalty")
+	fs.StringVar(&comparisonRates, "c", "comparison_rates.csv", "comparison rates CSV `file`")
+	fs.StringVar(&amount, "a", "10000", "extra amortization `amount`")
+	fs.StringVar(&when, "w", time.Now().Format(internal.DateLayout), "`date` of the extra amortization")
+	fs.StringVar(&addition, "i", "0", "`percentage` points added to the comparison rate")
+	lf.Register(fs)
+
+	fs.Parse(args)
+
+	in, err := lf.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	amountR, err := intio.ParseAmount(amount)
+	if err != nil {
+		log.Fatalf("failed to parse amount: %s", err)
+	}
+
+	day, err := time.Parse(internal.DateLayout, when)
+	if err != nil {
+		log.Fatalf("failed to read date of extra amortization: %s", err)
+	}
+
+	additionR, ok := new(big.Rat).SetString(addition)
+	if !ok {
+		log.Fatalf("failed to parse addition %q", addition)
+	}
+	additionR.Quo(additionR, big.NewRat(100, 1))
+
+	comparisonRatesL, err := intio.ReadInterestRates(comparisonRates, in.InComma)
+	if err != nil {
+		log.Fatalf("failed to read comparison rates: %s", err)
+	}
+
+	bank := in.NewBank()
+	p, err := bank.PrepaymentPenalty(day, amountR, comparisonRatesL, additionR)
+	if err != nil {
+		log.Fatalf("failed to calculate prepayment penalty: %s", err)
+	}
+
+	bigRat100 := big.NewRat(100, 1)
+
+	writer := csv.NewWriter(os.Stdout)
+	writer.Comma = in.OutComma
+
+	writer.Write([]string{
+		"Date",
+		"Amount",
+		"Fixed rate (%)",
+		"Comparison rate (%)",
+		"End of fixed-rate period",
+		"Remaining days",
+		"Penalty",
+	})
+
+	writer.Write([]string{
+		p.Day.Format(internal.DateLayout),
+		p.Amount.FloatString(2),
+		new(big.Rat).Mul(p.Period.DecimalRate, bigRat100).FloatString(2),
+		new(big.Rat).Mul(p.ComparisonRate, bigRat100).FloatString(2),
+		p.Period.End.Format(internal.DateLayout),
+		strconv.Itoa(p.RemainingDays),
+		p.Penalty.FloatString(2),
+	})
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Fatalf("failed to write prepayment penalty: %s", err)
+	}
+}
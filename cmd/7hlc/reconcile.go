@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/csv"
+	"log"
+	"math/big"
+	"os"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/calc"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/input"
+	intio "gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// reconcile compares the calculated loan with balances reported by
+// the bank and writes the deviations as CSV to standard output.
+func reconcile(args []string) {
+	var (
+		checkpoints string // -c flag
+		tolerance   string // -e flag
+		lf          input.Flags
+	)
+
+	fs := newFlagSet("reconcile")
+	fs.StringVar(&checkpoints, "c", "checkpoints.csv", "reported balances (checkpoints) CSV `file`")
+	fs.StringVar(&tolerance, "e", "0.01", "largest tolerated deviation `amount`")
+	lf.Register(fs)
+
+	fs.Parse(args)
+
+	in, err := lf.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tol, ok := new(big.Rat).SetString(tolerance)
+	if !ok {
+		log.Fatalf("failed to parse tolerance %q", tolerance)
+	}
+
+	checkpointsL, err := intio.ReadCheckpoints(checkpoints, in.InComma)
+	if err != nil {
+		log.Fatalf("failed to read checkpoints: %s", err)
+	}
+
+	deviations, err := calc.Reconcile(in.NewBank(), calc.NewLoan(in.Principal), in.FirstDay, checkpointsL, tol)
+	if err != nil {
+		log.Fatalf("failed to reconcile loan: %s", err)
+	}
+
+	log.Printf("%d of %d checkpoint(s) deviate by more than %s.",
+		len(deviations), len(checkpointsL), tol.FloatString(2))
+
+	writer := csv.NewWriter(os.Stdout)
+	writer.Comma = in.OutComma
+
+	writer.Write([]string{
+		"Date",
+		"Reported balance",
+		"Calculated balance",
+		"Balance deviation",
+		"Reported interest",
+		"Calculated interest",
+		"Interest deviation",
+		"Likely cause",
+		"Detail",
+	})
+
+	for _, d := range deviations {
+		reportedInterest, interestDiff := "-", "-"
+		if d.InterestDiff != nil {
+			reportedInterest = d.Checkpoint.Interest.FloatString(2)
+			interestDiff = d.InterestDiff.FloatString(2)
+		}
+
+		writer.Write([]string{
+			d.Checkpoint.Day.Format(internal.DateLayout),
+			d.Checkpoint.Balance.FloatString(2),
+			d.Balance.FloatString(2),
+			d.BalanceDiff.FloatString(2),
+			reportedInterest,
+			d.Interest.FloatString(2),
+			interestDiff,
+			d.Cause.String(),
+			d.Detail,
+		})
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		log.Fatalf("failed to write deviations: %s", err)
+	}
+}
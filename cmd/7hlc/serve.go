@@ -0,0 +1,46 @@
+package main
+
+import (
+	"log"
+	"net/http"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/server"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/web"
+)
+
+// serve serves the web UI, and the loans of a configuration file as
+// JSON over HTTP.
+func serve(args []string) {
+	var (
+		config string // -c flag
+		addr   string // -a flag
+	)
+
+	fs := newFlagSet("serve")
+	fs.StringVar(&config, "c", "loans.json", "loans configuration JSON `file`, or empty to serve only the web UI")
+	fs.StringVar(&addr, "a", "localhost:8080", "`address` to listen on")
+
+	fs.Parse(args)
+
+	var loans []server.LoanConfig
+	if config != "" {
+		var err error
+		loans, err = server.ReadConfig(config)
+		if err != nil {
+			log.Fatalf("failed to read configuration: %s", err)
+		}
+	}
+
+	api := server.New(loans)
+
+	mux := http.NewServeMux()
+	mux.Handle("/loans", api)
+	mux.Handle("/loans/", api)
+	mux.Handle("/", web.New())
+
+	log.Printf("Serving the web UI on http://%s/ and %d loan(s) on http://%s/loans.", addr, len(loans), addr)
+
+	if err := http.ListenAndServe(addr, mux); err != nil {
+		log.Fatalf("failed to serve: %s", err)
+	}
+}
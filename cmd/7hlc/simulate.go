@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/calc"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/input"
+)
+
+// simulate projects the loan from today with a payment plan along many
+// random interest rate paths and writes percentile bands of the
+// balance and cumulative interest as CSV to standard output.
+func simulate(args []string) {
+	var (
+		plan        string // -P flag
+		paths       int    // -N flag
+		seed        int64  // -s flag
+		percentiles string // -q flag
+		horizon     int    // -H flag
+		lf          input.Flags
+	)
+
+	fs := newFlagSet("simulate")
+	fs.StringVar(&plan, "P", "payment_plan.csv", "payment plan CSV `file` to project the loan with")
+	fs.IntVar(&paths, "N", 100, "`number` of interest rate paths")
+	fs.Int64Var(&seed, "s", 1, "`seed` of the random interest rate paths")
+	fs.StringVar(&percentiles, "q", "5,50,95", "comma-separated `percentiles` to report")
+	fs.IntVar(&horizon, "H", 10, "`years` to project the loan")
+	lf.Register(fs)
+
+	fs.Parse(args)
+
+	in, err := lf.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	planL, err := in.ReadPlan(plan)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	percentilesL, err := parsePercentiles(percentiles)
+	if err != nil {
+		log.Fatalf("failed to parse percentiles: %s", err)
+	}
+
+	if paths < 1 {
+		log.Fatalf("number of paths must be positive, got %d", paths)
+	}
+
+	last := time.Now().AddDate(horizon, 0, 0)
+
+	model, err := calc.RunSimulation(os.Stdout, in.FirstDay, in.Principal, in.NewBank(), planL, paths, seed, percentilesL, last, in.OutComma)
+	if err != nil {
+		log.Fatalf("failed to simulate loan: %s", err)
+	}
+
+	log.Printf("Simulated %d interest rate path(s) with %s.", paths, model)
+}
+
+// parsePercentiles parses comma-separated percentiles between 0 and
+// 100.
+func parsePercentiles(s string) ([]float64, error) {
+	var percentiles []float64
+
+	for _, f := range strings.Split(s, ",") {
+		p, err := strconv.ParseFloat(strings.TrimSpace(f), 64)
+		if err != nil {
+			return nil, err
+		}
+		if p < 0 || p > 100 {
+			return nil, fmt.Errorf("percentile %s out of range 0-100", f)
+		}
+		percentiles = append(percentiles, p)
+	}
+
+	return percentiles, nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/calc"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/input"
+)
+
+// stress projects the loan from today with a payment plan under a grid
+// of interest rate shifts and writes the outcome of each shift as CSV
+// to standard output.
+func stress(args []string) {
+	var (
+		plan    string // -P flag
+		shifts  string // -s flag
+		horizon int    // -H flag
+		lf      input.Flags
+	)
+
+	fs := newFlagSet("stress")
+	fs.StringVar(&plan, "P", "payment_plan.csv", "payment plan CSV `file` to project the loan with")
+	fs.StringVar(&shifts, "s", "-1:5:1", "rate shifts in percentage points as `from:to:step`")
+	fs.IntVar(&horizon, "H", 50, "largest number of `years` to project the loan")
+	lf.Register(fs)
+
+	fs.Parse(args)
+
+	in, err := lf.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	planL, err := in.ReadPlan(plan)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	shiftsL, err := parseShifts(shifts)
+	if err != nil {
+		log.Fatalf("failed to parse rate shifts: %s", err)
+	}
+
+	last := time.Now().AddDate(horizon, 0, 0)
+
+	if err := calc.RunStressTest(os.Stdout, in.FirstDay, in.Principal, in.NewBank(), planL, shiftsL, last, in.OutComma); err != nil {
+		log.Fatalf("failed to stress test loan: %s", err)
+	}
+}
+
+// parseShifts parses a grid of shifts in percentage points given as
+// from:to:step, both ends inclusive, and returns them in decimal form.
+func parseShifts(s string) ([]*big.Rat, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("want from:to:step, got %q", s)
+	}
+
+	var bounds [3]*big.Rat
+	for i, p := range parts {
+		r, ok := new(big.Rat).SetString(p)
+		if !ok {
+			return nil, fmt.Errorf("invalid number %q", p)
+		}
+		bounds[i] = r
+	}
+
+	from, to, step := bounds[0], bounds[1], bounds[2]
+	if step.Sign() <= 0 {
+		return nil, fmt.Errorf("step must be positive, got %s", parts[2])
+	}
+
+	var shifts []*big.Rat
+	for x := new(big.Rat).Set(from); x.Cmp(to) <= 0; x = new(big.Rat).Add(x, step) {
+		shifts = append(shifts, new(big.Rat).Quo(x, big.NewRat(100, 1)))
+	}
+
+	return shifts, nil
+}
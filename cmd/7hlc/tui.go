@@ -0,0 +1,41 @@
+package main
+
+import (
+	"log"
+	"os"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/calc"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/input"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/tui"
+)
+
+// explore shows the state of the loan on each day from the first day
+// through today in an interactive terminal UI.
+func explore(args []string) {
+	var lf input.Flags
+
+	fs := newFlagSet("tui")
+	lf.Register(fs)
+
+	fs.Parse(args)
+
+	in, err := lf.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	bank := in.NewBank()
+
+	days, err := calc.Series(&bank, calc.NewLoan(in.Principal), in.FirstDay, time.Now())
+	if err != nil {
+		log.Fatalf("failed to calculate loan: %s", err)
+	}
+	if len(days) == 0 {
+		log.Fatal("no days to show")
+	}
+
+	if err := tui.Run(days, os.Stdin, os.Stdout); err != nil {
+		log.Fatalf("failed to run terminal UI: %s", err)
+	}
+}
@@ -0,0 +1,194 @@
+package calc
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+	intio "gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// ErrNoEffectiveRate is returned by [EffectiveRate] when no rate
+// discounts the cash flows to zero.
+var ErrNoEffectiveRate = errors.New("no effective annual rate solves the cash flows")
+
+// CashFlow is an amount paid to (positive) or by (negative) the
+// borrower on a day.
+type CashFlow struct {
+	Day    time.Time
+	Amount *big.Rat
+}
+
+// CashFlows returns the borrower's cash flows of a loan with the given
+// principal processed by bank over days: the principal and
+// disbursements paid out to the borrower, and the payments made by the
+// borrower. Whatever is still owed at the end of the last day,
+// including unpaid fees, is treated as paid then. Fees and refunds are
+// not cash flows of their own; they change what the payments settle.
+func CashFlows(bank *Bank, principal *big.Rat, days []Day) []CashFlow {
+	if len(days) == 0 {
+		return nil
+	}
+
+	flows := []CashFlow{{Day: days[0].Date, Amount: new(big.Rat).Set(principal)}}
+
+	for _, day := range days {
+		for _, t := range bank.transactionsOn(day.Date) {
+			abs := new(big.Rat).Abs(t.Amount)
+
+			switch t.Kind {
+			case intio.Payment:
+				flows = append(flows, CashFlow{Day: day.Date, Amount: abs.Neg(abs)})
+			case intio.Disbursement:
+				flows = append(flows, CashFlow{Day: day.Date, Amount: abs})
+			}
+		}
+	}
+
+	last := days[len(days)-1]
+	if owed := last.Loan.owed(); owed.Sign() != 0 {
+		flows = append(flows, CashFlow{Day: last.Date, Amount: owed.Neg(owed)})
+	}
+
+	return flows
+}
+
+// EffectiveRate returns the effective annual rate (effektiv ränta) of
+// the cash flows, i.e. the rate X that solves
+//
+//	Σ amount × (1 + X)^(−t) = 0
+//
+// where t is the time of each cash flow in years (days over 365) since
+// the first one. The rate is found by bisection between −99 % and
+// 1000 %.
+func EffectiveRate(flows []CashFlow) (float64, error) {
+	if len(flows) == 0 {
+		return 0, ErrNoEffectiveRate
+	}
+
+	first := flows[0].Day
+	for _, f := range flows {
+		if f.Day.Before(first) {
+			first = f.Day
+		}
+	}
+
+	years := make([]float64, len(flows))
+	amounts := make([]float64, len(flows))
+	for i, f := range flows {
+		years[i] = f.Day.Sub(first).Hours() / 24 / 365
+		amounts[i], _ = f.Amount.Float64()
+	}
+
+	npv := func(x float64) float64 {
+		var sum float64
+		for i := range flows {
+			sum += amounts[i] * math.Pow(1+x, -years[i])
+		}
+		return sum
+	}
+
+	lo, hi := -0.99, 10.0
+	npvLo, npvHi := npv(lo), npv(hi)
+	if npvLo == 0 {
+		return lo, nil
+	}
+	if math.Signbit(npvLo) == math.Signbit(npvHi) {
+		return 0, ErrNoEffectiveRate
+	}
+
+	for i := 0; i < 200 && hi-lo > 1e-12; i++ {
+		mid := (lo + hi) / 2
+		if npvMid := npv(mid); math.Signbit(npvMid) == math.Signbit(npvLo) {
+			lo, npvLo = mid, npvMid
+		} else {
+			hi = mid
+		}
+	}
+
+	return (lo + hi) / 2, nil
+}
+
+// RunEffectiveRate runs the calculations like [Run] and writes the
+// effective annual rate of the loan to w as CSV records: first the
+// historical rate, treating the amount owed today as paid today, then,
+// unless plan is empty, the projected rate when the loan is paid
+// according to plan from tomorrow until it is paid off or until the
+// last day. Finally, a record is written for each period with the
+// same nominal annual interest rate.
+func RunEffectiveRate(w io.Writer, firstDay time.Time, principal *big.Rat, bank Bank, plan []intio.PlannedPayment, last time.Time, outComma rune) error {
+	today := DateFromTime(time.Now())
+	days, err := Series(&bank, NewLoan(principal), firstDay, today)
+	if err != nil {
+		return err
+	}
+	if len(days) == 0 {
+		return fmt.Errorf("loan starts after today")
+	}
+
+	historical, err := EffectiveRate(CashFlows(&bank, principal, days))
+	if err != nil {
+		return fmt.Errorf("historical: %w", err)
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = outComma
+
+	writer.Write([]string{"Rate", "From", "To", "Annual rate (%)"})
+
+	writeRate := func(name string, from, to time.Time, rate string) {
+		writer.Write([]string{
+			name,
+			from.Format(internal.DateLayout),
+			to.Format(internal.DateLayout),
+			rate,
+		})
+	}
+
+	writeRate("Effective (historical)", days[0].Date, today, strconv.FormatFloat(historical*100, 'f', 2, 64))
+
+	if len(plan) > 0 {
+		projected, b, err := Project(bank, days[len(days)-1].Loan, today.AddDate(0, 0, 1), last, plan)
+		if err != nil {
+			return err
+		}
+		days = append(days, projected...)
+
+		rate, err := EffectiveRate(CashFlows(&b, principal, days))
+		if err != nil {
+			return fmt.Errorf("projected: %w", err)
+		}
+
+		writeRate("Effective (projected)", days[0].Date, days[len(days)-1].Date, strconv.FormatFloat(rate*100, 'f', 2, 64))
+	}
+
+	for start := 0; start < len(days); {
+		end := start + 1
+		for end < len(days) && sameRate(days[start].Rate, days[end].Rate) {
+			end++
+		}
+
+		rate := "-"
+		if r := days[start].Rate; r != nil {
+			rate = new(big.Rat).Mul(r, big.NewRat(100, 1)).FloatString(2)
+		}
+
+		writeRate("Nominal", days[start].Date, days[end-1].Date, rate)
+		start = end
+	}
+
+	return flush(writer)
+}
+
+func sameRate(a, b *big.Rat) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Cmp(b) == 0
+}
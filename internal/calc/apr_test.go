@@ -0,0 +1,139 @@
+package calc
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"math"
+	"path"
+	"testing"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+func TestEffectiveRate(t *testing.T) {
+	tests := []struct {
+		name  string
+		flows []CashFlow
+		want  float64
+	}{
+		{
+			name: "one year",
+			flows: []CashFlow{
+				{Day: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Amount: mustBigRatFromString("1000")},
+				{Day: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Amount: mustBigRatFromString("-1100")},
+			},
+			want: 0.10,
+		},
+		{
+			name: "two years",
+			flows: []CashFlow{
+				{Day: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Amount: mustBigRatFromString("1000")},
+				{Day: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Amount: mustBigRatFromString("-1210")},
+			},
+			want: 0.10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EffectiveRate(tt.flows)
+			if err != nil {
+				t.Fatalf("calculating effective rate: %s", err)
+			}
+
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("want %f, but got %f", tt.want, got)
+			}
+		})
+	}
+
+	t.Run("no solution", func(t *testing.T) {
+		_, err := EffectiveRate([]CashFlow{
+			{Day: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Amount: mustBigRatFromString("1000")},
+			{Day: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Amount: mustBigRatFromString("1000")},
+		})
+		if !errors.Is(err, ErrNoEffectiveRate) {
+			t.Errorf("want %v, but got %v", ErrNoEffectiveRate, err)
+		}
+	})
+}
+
+func TestCashFlows_EffectiveRate(t *testing.T) {
+	bank := NewBank(nil, []io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 1, 1, "0.03"),
+	})
+	principal := mustBigRatFromString("10000")
+	first := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(2030, 12, 31, 0, 0, 0, 0, time.UTC)
+	plan := []io.PlannedPayment{{From: first, Day: 27, Amount: mustBigRatFromString("500")}}
+
+	days, b := mustProject(t, bank, NewLoan(principal), first, last, plan)
+
+	rate, err := EffectiveRate(CashFlows(&b, principal, days))
+	if err != nil {
+		t.Fatalf("calculating effective rate: %s", err)
+	}
+
+	// Monthly capitalization makes the effective rate slightly higher
+	// than the nominal rate.
+	if rate < 0.03 || rate > 0.031 {
+		t.Errorf("want effective rate just above 3 %%, but got %f", rate)
+	}
+
+	bank.AddFees(io.ScheduledFee{Monthly: true, Start: first, Amount: mustBigRatFromString("29")})
+	days, b = mustProject(t, bank, NewLoan(principal), first, last, plan)
+
+	withFees, err := EffectiveRate(CashFlows(&b, principal, days))
+	if err != nil {
+		t.Fatalf("calculating effective rate: %s", err)
+	}
+
+	if withFees < rate+0.05 {
+		t.Errorf("want effective rate with fees well above %f, but got %f", rate, withFees)
+	}
+}
+
+func TestRunEffectiveRate(t *testing.T) {
+	transactions, err := io.ReadTransactions(path.Join("..", "testdata", "transactions.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading transactions: %s", err)
+	}
+
+	rates, err := io.ReadInterestRates(path.Join("..", "testdata", "annual_interest_rates.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading interest rates: %s", err)
+	}
+
+	plan, err := io.ReadPaymentPlan(path.Join("..", "testdata", "payment_plan.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading payment plan: %s", err)
+	}
+
+	firstDay := time.Date(2022, time.June, 7, 0, 0, 0, 0, time.UTC)
+	last := time.Now().AddDate(20, 0, 0)
+
+	var outCSV bytes.Buffer
+	err = RunEffectiveRate(&outCSV, firstDay, mustBigRatFromString("100000"), NewBank(transactions, rates), plan, last, ',')
+	if err != nil {
+		t.Fatalf("running effective rate: %s", err)
+	}
+
+	records, err := csv.NewReader(&outCSV).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV output: %s", err)
+	}
+
+	t.Logf("%v", records)
+
+	if want, got := []string{"Effective (historical)", "Effective (projected)", "Nominal"},
+		[]string{records[1][0], records[2][0], records[3][0]}; !equalStrings(want, got) {
+		t.Errorf("want %v, but got %v", want, got)
+	}
+
+	// The rate of the last nominal period is 4.54 %.
+	if want, got := "4.54", records[len(records)-1][3]; want != got {
+		t.Errorf("want last nominal rate %s, but got %s", want, got)
+	}
+}
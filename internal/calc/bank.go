@@ -18,8 +18,22 @@ func init() {
 }
 
 type Bank struct {
-	transactions  []io.Transaction
-	interestRates []io.AnnualInterestRate
+	transactions []io.Transaction
+	// transactionsByDay indexes transactions by their day (see
+	// DateFromTime), so that the transactions of a day are found
+	// without scanning all of them.
+	transactionsByDay map[time.Time][]io.Transaction
+	// planned holds the payments made by a payment plan in a
+	// projection by day (see Project), apart from the transactions so
+	// that they can be added one at a time.
+	planned          map[time.Time][]io.Transaction
+	interestRates    []io.AnnualInterestRate
+	fixedRatePeriods []io.FixedRatePeriod
+	fees             []io.ScheduledFee
+	minimumPayments  []io.PlannedPayment
+	penaltyRates     []io.AnnualInterestRate
+	overpayment      OverpaymentPolicy
+	depositRates     []io.AnnualInterestRate
 }
 
 func NewBank(transactions []io.Transaction, interestRates []io.AnnualInterestRate) Bank {
@@ -32,28 +46,153 @@ func NewBank(transactions []io.Transaction, interestRates []io.AnnualInterestRat
 	})
 
 	return Bank{
-		transactions:  transactions,
-		interestRates: interestRates,
+		transactions:      transactions,
+		transactionsByDay: indexTransactions(transactions),
+		interestRates:     interestRates,
 	}
 }
 
+// indexTransactions returns transactions, which must be sorted by date,
+// grouped by day.
+func indexTransactions(transactions []io.Transaction) map[time.Time][]io.Transaction {
+	index := make(map[time.Time][]io.Transaction)
+
+	for _, t := range transactions {
+		day := DateFromTime(t.Date)
+		index[day] = append(index[day], t)
+	}
+
+	return index
+}
+
+// AddFixedRatePeriods adds periods during which the annual interest
+// rate is fixed. On days within a fixed-rate period, its rate applies
+// instead of the floating interest rate.
+func (b *Bank) AddFixedRatePeriods(periods ...io.FixedRatePeriod) {
+	fixed := make([]io.FixedRatePeriod, 0, len(b.fixedRatePeriods)+len(periods))
+	fixed = append(fixed, b.fixedRatePeriods...)
+	fixed = append(fixed, periods...)
+
+	sort.SliceStable(fixed, func(i int, j int) bool {
+		return fixed[i].Start.Before(fixed[j].Start)
+	})
+
+	b.fixedRatePeriods = fixed
+}
+
+// AddFees adds scheduled fees. Fees, like transactions of the fee
+// kind, are tracked separately from the balance and do not accrue
+// interest.
+func (b *Bank) AddFees(fees ...io.ScheduledFee) {
+	b.fees = append(b.fees[:len(b.fees):len(b.fees)], fees...)
+}
+
+// withTransactions returns a copy of b that uses the given
+// transactions instead of its own.
+func (b Bank) withTransactions(transactions []io.Transaction) Bank {
+	sort.SliceStable(transactions, func(i int, j int) bool {
+		return transactions[i].Date.Before(transactions[j].Date)
+	})
+
+	b.transactions = transactions
+	b.transactionsByDay = indexTransactions(transactions)
+	return b
+}
+
+// withShare returns a copy of b that charges share of each scheduled
+// fee and requires share of each minimum payment, e.g. for the part of
+// a loan owned by one of its borrowers.
+func (b Bank) withShare(share *big.Rat) Bank {
+	fees := make([]io.ScheduledFee, len(b.fees))
+	for i, f := range b.fees {
+		f.Amount = new(big.Rat).Mul(f.Amount, share)
+		fees[i] = f
+	}
+
+	plan := make([]io.PlannedPayment, len(b.minimumPayments))
+	for i, p := range b.minimumPayments {
+		p.Amount = new(big.Rat).Mul(p.Amount, share)
+		plan[i] = p
+	}
+
+	b.fees = fees
+	b.minimumPayments = plan
+	return b
+}
+
+// withInterestRates returns a copy of b that uses the given interest
+// rates instead of its own.
+func (b Bank) withInterestRates(interestRates []io.AnnualInterestRate) Bank {
+	sort.SliceStable(interestRates, func(i int, j int) bool {
+		return interestRates[i].Day.Before(interestRates[j].Day)
+	})
+
+	b.interestRates = interestRates
+	return b
+}
+
 // Process takes as input the state of a loan at the beginning of the
 // given day and returns the state of the loan at the end of the same
-// day.
-func (b *Bank) Process(day time.Time, in Loan) (out Loan) {
+// day. Fees charged during the day are added to the unpaid fees, which
+// are settled by payments, followed by any penalty interest, before
+// the balance is reduced. Payments in excess of what is owed are
+// handled according to the overpayment policy (see
+// [Bank.SetOverpaymentPolicy]).
+//
+// A [*RateNotFoundError] is returned if no interest rate covers day,
+// and an [*OverpaidError] if the overpayment policy does not allow an
+// overpayment made during the day.
+func (b *Bank) Process(day time.Time, in Loan) (out Loan, err error) {
+	out, _, err = b.process(day, in)
+	return out, err
+}
+
+// accruals is what process accrues, or finds due, during a day besides
+// the ordinary interest.
+type accruals struct {
+	// due is the minimum payment due, or nil if none is due.
+	due *Due
+	// penalty is the penalty interest accrued.
+	penalty *big.Rat
+	// credit is the credit interest accrued on the surplus.
+	credit *big.Rat
+}
+
+// process is like Process but also returns the accruals of the day.
+func (b *Bank) process(day time.Time, in Loan) (out Loan, a accruals, err error) {
 	out = CopyLoan(in)
 
 	if _, _, d := day.Date(); d == 1 {
 		out.balance.Add(out.balance, out.interest)
 		out.interest.Set(new(big.Rat))
+		out.surplus.Add(out.surplus, out.credit)
+		out.credit.Set(new(big.Rat))
 	}
 
+	out.fees.Add(out.fees, b.feesAmount(day))
+
 	trans := b.transactionsAmount(day)
+	if trans.Sign() > 0 {
+		for _, unpaid := range []*big.Rat{out.fees, out.penalty} {
+			settled := new(big.Rat).Set(unpaid)
+			if settled.Cmp(trans) > 0 {
+				settled.Set(trans)
+			}
+			unpaid.Sub(unpaid, settled)
+			trans.Sub(trans, settled)
+		}
+	}
 	out.balance.Sub(out.balance, trans)
 
+	if err := b.settleOverpayment(day, &out); err != nil {
+		return in, a, err
+	}
+
+	a.due = b.checkMinimumPayment(day, &out)
+
 	rate, ok := b.annualInterestRate(day)
 	if !ok {
-		panic("annual interest rate not found")
+		return in, a, &RateNotFoundError{Day: DateFromTime(day)}
 	}
 
 	y, m, _ := day.Date()
@@ -62,39 +201,122 @@ func (b *Bank) Process(day time.Time, in Loan) (out Loan) {
 
 	out.interest.Add(out.interest, dayInterest)
 
-	return out
+	a.penalty, err = b.penaltyInterest(day, out.overdue)
+	if err != nil {
+		return in, a, err
+	}
+	out.penalty.Add(out.penalty, a.penalty)
+
+	a.credit = b.creditInterest(day, out.surplus)
+	out.credit.Add(out.credit, a.credit)
+
+	return out, a, nil
 }
 
+// transactionsAmount returns the net amount by which the transactions
+// on day reduce the amount owed. Payments and refunds reduce it,
+// whereas disbursements increase it, regardless of the sign of their
+// amounts. Fees are left out since they are tracked separately (see
+// feesAmount), as are interest charges since interest is calculated.
 func (b *Bank) transactionsAmount(day time.Time) *big.Rat {
-	y, m, d := day.Date()
-	day = time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
 	amount := new(big.Rat)
 
-	for _, t := range b.transactions {
-		if ty, tm, td := t.Date.Date(); ty == y && tm == m && td == d {
-			amount.Add(amount, t.Amount)
-		} else if t.Date.After(day) {
-			break
+	for _, t := range b.transactionsOn(day) {
+		abs := new(big.Rat).Abs(t.Amount)
+
+		switch t.Kind {
+		case io.Payment, io.Refund:
+			amount.Add(amount, abs)
+		case io.Disbursement:
+			amount.Sub(amount, abs)
+		}
+	}
+
+	return amount
+}
+
+// feesAmount returns the sum of the scheduled fees and the fee
+// transactions charged on day.
+func (b *Bank) feesAmount(day time.Time) *big.Rat {
+	amount := new(big.Rat)
+	day = DateFromTime(day)
+
+	for _, f := range b.fees {
+		if f.ChargedOn(day) {
+			amount.Add(amount, f.Amount)
+		}
+	}
+
+	for _, t := range b.transactionsOn(day) {
+		if t.Kind == io.Fee {
+			amount.Add(amount, new(big.Rat).Abs(t.Amount))
+		}
+	}
+
+	return amount
+}
+
+// paymentsAmount returns the sum of the payments made on day.
+func (b *Bank) paymentsAmount(day time.Time) *big.Rat {
+	amount := new(big.Rat)
+
+	for _, t := range b.transactionsOn(day) {
+		if t.Kind == io.Payment {
+			amount.Add(amount, new(big.Rat).Abs(t.Amount))
 		}
 	}
 
 	return amount
 }
 
+// transactionsOn returns the transactions made on day, followed by any
+// planned payments. The returned slice must not be modified.
+func (b *Bank) transactionsOn(day time.Time) []io.Transaction {
+	day = DateFromTime(day)
+
+	transactions := b.transactionsByDay[day]
+	planned := b.planned[day]
+	if len(planned) == 0 {
+		return transactions
+	}
+
+	return append(transactions[:len(transactions):len(transactions)], planned...)
+}
+
 func (b *Bank) annualInterestRate(day time.Time) (rate *big.Rat, ok bool) {
+	if p, ok := b.fixedRatePeriod(day); ok {
+		return new(big.Rat).Set(p.DecimalRate), true
+	}
+	return rateOn(b.interestRates, day)
+}
+
+// fixedRatePeriod returns the fixed-rate period containing day.
+func (b *Bank) fixedRatePeriod(day time.Time) (io.FixedRatePeriod, bool) {
 	day = DateFromTime(day)
-	rate = new(big.Rat)
-
-	for _, r := range b.interestRates {
-		if r.Day.After(day) {
-			return rate, ok
-		} else {
-			rate.Set(r.DecimalRate)
-			ok = true
+
+	for _, p := range b.fixedRatePeriods {
+		if p.Contains(day) {
+			return p, true
 		}
 	}
 
-	return rate, ok
+	return io.FixedRatePeriod{}, false
+}
+
+// rateOn returns the rate in effect on day among rates, which must be
+// sorted by day. It is found by binary search for the last rate
+// changed on or before day.
+func rateOn(rates []io.AnnualInterestRate, day time.Time) (rate *big.Rat, ok bool) {
+	day = DateFromTime(day)
+
+	i := sort.Search(len(rates), func(i int) bool {
+		return rates[i].Day.After(day)
+	})
+	if i == 0 {
+		return new(big.Rat), false
+	}
+
+	return new(big.Rat).Set(rates[i-1].DecimalRate), true
 }
 
 func daysInMonth(m time.Month, year int) int {
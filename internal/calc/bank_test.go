@@ -32,7 +32,10 @@ func TestProcess(t *testing.T) {
 		interest: mustBigRatFromString("2.75"),
 	}
 
-	got := bank.Process(day, loan)
+	got, err := bank.Process(day, loan)
+	if err != nil {
+		t.Fatalf("processing loan: %s", err)
+	}
 
 	if cmp := want.balance.Cmp(got.balance); cmp != 0 {
 		t.Errorf("want balance %s, but got %s (cmp=%d)", want.balance, got.balance, cmp)
@@ -200,3 +203,144 @@ func mustBigRatFromString(s string) *big.Rat {
 		panic(fmt.Errorf("set big rat to %q", s))
 	}
 }
+
+func TestAnnualInterestRate_FixedRatePeriod(t *testing.T) {
+	bank := NewBank([]io.Transaction{}, []io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 1, 1, "0.0114"),
+		io.MustNewAnnualInterestRate(2022, 7, 6, "0.0164"),
+	})
+	bank.AddFixedRatePeriods(io.FixedRatePeriod{
+		Start:       time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC),
+		DecimalRate: mustBigRatFromString("0.02"),
+	})
+
+	tests := []struct {
+		date     time.Time
+		wantRate *big.Rat
+	}{
+		{time.Date(2022, 2, 28, 0, 0, 0, 0, time.UTC), mustBigRatFromString("0.0114")},
+		{time.Date(2022, 3, 1, 0, 0, 0, 0, time.UTC), mustBigRatFromString("0.02")},
+		{time.Date(2022, 7, 6, 12, 0, 0, 0, time.UTC), mustBigRatFromString("0.02")},
+		{time.Date(2022, 9, 1, 0, 0, 0, 0, time.UTC), mustBigRatFromString("0.0164")},
+	}
+
+	for _, tt := range tests {
+		rate, ok := bank.annualInterestRate(tt.date)
+
+		if !ok || tt.wantRate.Cmp(rate) != 0 {
+			t.Errorf("%s: want rate %s, but got %s (ok=%t)", tt.date, tt.wantRate, rate, ok)
+		}
+	}
+}
+
+func TestTransactionsAmount_Kinds(t *testing.T) {
+	transactions := []io.Transaction{
+		io.MustNewTransaction(2022, 1, 2, "1000"),
+		io.MustNewTransaction(2022, 1, 2, "-300"),
+		io.MustNewTransaction(2022, 1, 2, "50"),
+		io.MustNewTransaction(2022, 1, 2, "20"),
+		io.MustNewTransaction(2022, 1, 2, "-400"),
+	}
+	transactions[1].Kind = io.Disbursement
+	transactions[2].Kind = io.Fee
+	transactions[3].Kind = io.Refund
+	transactions[4].Kind = io.InterestCharge
+
+	bank := NewBank(transactions, []io.AnnualInterestRate{})
+	day := time.Date(2022, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	if want, got := mustBigRatFromString("720"), bank.transactionsAmount(day); want.Cmp(got) != 0 {
+		t.Errorf("want amount %s, got %s", want, got)
+	}
+
+	if want, got := mustBigRatFromString("50"), bank.feesAmount(day); want.Cmp(got) != 0 {
+		t.Errorf("want fees %s, got %s", want, got)
+	}
+
+	if want, got := mustBigRatFromString("1000"), bank.paymentsAmount(day); want.Cmp(got) != 0 {
+		t.Errorf("want payments %s, got %s", want, got)
+	}
+}
+
+// benchmarkBank returns a bank with a monthly payment and a monthly
+// interest rate change over the given number of years from 2000.
+func benchmarkBank(years int) Bank {
+	var transactions []io.Transaction
+	var rates []io.AnnualInterestRate
+
+	for m := 0; m < years*12; m++ {
+		y, mo := 2000+m/12, time.Month(m%12+1)
+		transactions = append(transactions, io.MustNewTransaction(y, mo, 25, "500"))
+		rates = append(rates, io.MustNewAnnualInterestRate(y, mo, 1, fmt.Sprintf("0.0%d", 1+m%9)))
+	}
+
+	return NewBank(transactions, rates)
+}
+
+func BenchmarkTransactionsAmount(b *testing.B) {
+	bank := benchmarkBank(30)
+	day := time.Date(2029, 12, 25, 0, 0, 0, 0, time.UTC)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bank.transactionsAmount(day)
+	}
+}
+
+func BenchmarkAnnualInterestRate(b *testing.B) {
+	bank := benchmarkBank(30)
+	day := time.Date(2029, 12, 25, 0, 0, 0, 0, time.UTC)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		bank.annualInterestRate(day)
+	}
+}
+
+// BenchmarkSeries processes the last year of a 30-year loan, where the
+// lookups have the most transactions and rates to get through.
+func BenchmarkSeries(b *testing.B) {
+	bank := benchmarkBank(30)
+	first := time.Date(2029, 1, 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(2029, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Series(&bank, NewLoan(big.NewRat(1000000, 1)), first, last); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkSeriesAt is like BenchmarkSeries but asks for the last day
+// only, so that the quiet days between the payments are jumped over.
+func BenchmarkSeriesAt(b *testing.B) {
+	bank := benchmarkBank(30)
+	first := time.Date(2029, 1, 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(2029, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := SeriesAt(&bank, NewLoan(big.NewRat(1000000, 1)), first, last, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkProject projects the last ten years of a 30-year loan with a
+// monthly planned payment, so that the planned payments pile up on top
+// of the transactions of the bank.
+func BenchmarkProject(b *testing.B) {
+	bank := benchmarkBank(30)
+	first := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(2029, 12, 31, 0, 0, 0, 0, time.UTC)
+	plan := []io.PlannedPayment{{From: first, Day: 27, Amount: big.NewRat(1000, 1)}}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, _, err := Project(bank, NewLoan(big.NewRat(1000000, 1)), first, last, plan); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
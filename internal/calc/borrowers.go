@@ -0,0 +1,244 @@
+package calc
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+	intio "gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// borrowerLoan is one borrower's part of a loan shared by several
+// borrowers.
+type borrowerLoan struct {
+	borrower intio.Borrower
+	bank     Bank
+	days     []Day
+}
+
+// splitBorrowers splits a loan between borrowers. Each borrower owns
+// their ownership share of the principal, the scheduled fees, and the
+// minimum payments, and carries the interest on their own part, which
+// is reduced by their own transactions only. Since interest is
+// proportional to the balance, the borrowers' parts always add up to
+// the whole loan, except for overdue payments if one borrower pays
+// more than their share of a minimum payment and another less.
+func splitBorrowers(bank Bank, principal *big.Rat, first, last time.Time, borrowers []intio.Borrower, attributions []intio.Attribution) ([]borrowerLoan, error) {
+	shares := make([]share, len(borrowers))
+	for i, b := range borrowers {
+		shares[i] = share{name: b.Name, share: b.Share}
+	}
+	if err := checkShares("borrower", shares); err != nil {
+		return nil, err
+	}
+
+	transactions, err := attribute(bank.transactions, borrowers, attributions)
+	if err != nil {
+		return nil, err
+	}
+
+	loans := make([]borrowerLoan, len(borrowers))
+	for i, b := range borrowers {
+		bb := bank.withTransactions(transactions[i]).withShare(b.Share)
+		days, err := Series(&bb, NewLoan(new(big.Rat).Mul(principal, b.Share)), first, last)
+		if err != nil {
+			return nil, fmt.Errorf("borrower %q: %w", b.Name, err)
+		}
+		loans[i] = borrowerLoan{borrower: b, bank: bb, days: days}
+	}
+
+	return loans, nil
+}
+
+// attribute returns the transactions of each borrower, in the order
+// of borrowers. A transaction is attributed to the borrower named by
+// a matching attribution (same date and amount), or else to the first
+// borrower whose pattern matches its description. Other transactions
+// are split between all borrowers according to their ownership
+// shares.
+func attribute(transactions []intio.Transaction, borrowers []intio.Borrower, attributions []intio.Attribution) ([][]intio.Transaction, error) {
+	index := make(map[string]int, len(borrowers))
+	for i, b := range borrowers {
+		index[b.Name] = i
+	}
+
+	used := make([]bool, len(attributions))
+	attributed := make([][]intio.Transaction, len(borrowers))
+
+next:
+	for _, t := range transactions {
+		for i, a := range attributions {
+			if used[i] || !a.Date.Equal(t.Date) || a.Amount.Cmp(t.Amount) != 0 {
+				continue
+			}
+
+			b, ok := index[a.Borrower]
+			if !ok {
+				return nil, fmt.Errorf("attribution on %s to unknown borrower %q",
+					a.Date.Format(internal.DateLayout), a.Borrower)
+			}
+
+			used[i] = true
+			attributed[b] = append(attributed[b], t)
+			continue next
+		}
+
+		for b, borrower := range borrowers {
+			if borrower.Pattern != nil && borrower.Pattern.MatchString(t.Description) {
+				attributed[b] = append(attributed[b], t)
+				continue next
+			}
+		}
+
+		for b, borrower := range borrowers {
+			attributed[b] = append(attributed[b], splitTransactions([]intio.Transaction{t}, borrower.Share)...)
+		}
+	}
+
+	for i, a := range attributions {
+		if !used[i] {
+			return nil, fmt.Errorf("attribution of %s on %s to %q matches no transaction",
+				a.Amount.FloatString(2), a.Date.Format(internal.DateLayout), a.Borrower)
+		}
+	}
+
+	return attributed, nil
+}
+
+// RunBorrowers runs the calculations for a loan shared by borrowers,
+// given the first day of the loan, the principal, and a bank holding
+// the transactions made and the interest rate changes. Results are
+// written to w as CSV records—one record per day—indicating, for each
+// borrower, the payments made and interest carried so far, and the
+// borrower's outstanding balance and share of the total balance.
+func RunBorrowers(w io.Writer, firstDay time.Time, principal *big.Rat, bank Bank, borrowers []intio.Borrower, attributions []intio.Attribution, outComma rune) error {
+	loans, err := splitBorrowers(bank, principal, firstDay, time.Now(), borrowers, attributions)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = outComma
+
+	header := []string{"Date"}
+	for _, l := range loans {
+		name := l.borrower.Name
+		header = append(header,
+			name+" payments",
+			name+" interest",
+			name+" balance",
+			name+" share of balance (%)")
+	}
+	writer.Write(header)
+
+	paid := make([]*big.Rat, len(loans))
+	interest := make([]*big.Rat, len(loans))
+	for i := range loans {
+		paid[i], interest[i] = new(big.Rat), new(big.Rat)
+	}
+
+	for d := range loans[0].days {
+		record := []string{loans[0].days[d].Date.Format(internal.DateLayout)}
+
+		total := new(big.Rat)
+		for _, l := range loans {
+			total.Add(total, l.days[d].Loan.balance)
+		}
+
+		for i, l := range loans {
+			day := l.days[d]
+			paid[i].Add(paid[i], l.bank.paymentsAmount(day.Date))
+			interest[i].Add(interest[i], day.Interest)
+
+			record = append(record,
+				paid[i].FloatString(2),
+				interest[i].FloatString(2),
+				day.Loan.balance.FloatString(2),
+				percentOf(day.Loan.balance, total))
+		}
+
+		writer.Write(record)
+	}
+
+	return flush(writer)
+}
+
+// BorrowerStatement writes the yearly statement of each borrower of a
+// loan to w as CSV records—one record per borrower—with the payments
+// made and interest carried during the year, and the outstanding
+// balance and share of the total balance at the end of the year (or
+// today, if the year has not ended).
+func BorrowerStatement(w io.Writer, firstDay time.Time, principal *big.Rat, bank Bank, borrowers []intio.Borrower, attributions []intio.Attribution, year int, outComma rune) error {
+	loans, err := splitBorrowers(bank, principal, firstDay, time.Now(), borrowers, attributions)
+	if err != nil {
+		return err
+	}
+
+	first := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := first.AddDate(1, 0, 0)
+
+	days := loans[0].days
+	if len(days) == 0 || !days[0].Date.Before(end) || days[len(days)-1].Date.Before(first) {
+		return fmt.Errorf("loan has no days in %d", year)
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = outComma
+
+	writer.Write([]string{
+		"Borrower",
+		"Year",
+		"Ownership share (%)",
+		"Payments",
+		"Interest",
+		"Balance",
+		"Share of balance (%)",
+	})
+
+	last := -1
+	for d, day := range days {
+		if day.Date.Before(end) {
+			last = d
+		}
+	}
+
+	total := new(big.Rat)
+	for _, l := range loans {
+		total.Add(total, l.days[last].Loan.balance)
+	}
+
+	for _, l := range loans {
+		days := l.days[:last+1]
+		for len(days) > 1 && days[0].Date.Before(first) {
+			days = days[1:]
+		}
+		s := Summarize(&l.bank, days)
+
+		balance := l.days[last].Loan.balance
+
+		writer.Write([]string{
+			l.borrower.Name,
+			fmt.Sprint(year),
+			new(big.Rat).Mul(l.borrower.Share, big.NewRat(100, 1)).FloatString(2),
+			s.Payments.FloatString(2),
+			s.Interest.FloatString(2),
+			balance.FloatString(2),
+			percentOf(balance, total),
+		})
+	}
+
+	return flush(writer)
+}
+
+// percentOf returns x as a percentage of total, or "-" if total is
+// zero.
+func percentOf(x, total *big.Rat) string {
+	if total.Sign() == 0 {
+		return "-"
+	}
+	p := new(big.Rat).Quo(x, total)
+	return p.Mul(p, big.NewRat(100, 1)).FloatString(2)
+}
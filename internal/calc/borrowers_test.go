@@ -0,0 +1,193 @@
+package calc
+
+import (
+	"bytes"
+	"encoding/csv"
+	"math/big"
+	"path"
+	"testing"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+func TestAttribute(t *testing.T) {
+	transactions, err := io.ReadTransactions(path.Join("..", "testdata", "transactions.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading transactions: %s", err)
+	}
+
+	borrowers, err := io.ReadBorrowers(path.Join("..", "testdata", "borrowers.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading borrowers: %s", err)
+	}
+
+	attributions, err := io.ReadAttributions(path.Join("..", "testdata", "attributions.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading attributions: %s", err)
+	}
+
+	got, err := attribute(transactions, borrowers, attributions)
+	if err != nil {
+		t.Fatalf("attributing transactions: %s", err)
+	}
+
+	// Anna: RÄNTA+AMOR by pattern, one ÖVERFÖRING by attribution, and
+	// half of ÅTERBETALN. Bertil: the other ÖVERFÖRING by pattern and
+	// the other half of ÅTERBETALN.
+	want := []string{"4871.90", "3668.00"}
+
+	for i, ts := range got {
+		sum := mustBigRatFromString("0")
+		for _, t := range ts {
+			sum.Add(sum, t.Amount)
+		}
+
+		if got := sum.FloatString(2); got != want[i] {
+			t.Errorf("%s: want sum %s, but got %s", borrowers[i].Name, want[i], got)
+		}
+	}
+}
+
+func TestAttribute_UnusedAttribution(t *testing.T) {
+	borrowers := []io.Borrower{{Name: "A", Share: mustBigRatFromString("1")}}
+	attributions := []io.Attribution{{
+		Date:     time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		Amount:   mustBigRatFromString("100"),
+		Borrower: "A",
+	}}
+
+	if _, err := attribute(nil, borrowers, attributions); err == nil {
+		t.Error("want error, but got nil")
+	}
+}
+
+func TestSplitBorrowers_FeesAndMinimumPayments(t *testing.T) {
+	first := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(2022, 4, 30, 0, 0, 0, 0, time.UTC)
+
+	bank := NewBank([]io.Transaction{
+		io.MustNewTransaction(2022, 1, 10, "1000"),
+		io.MustNewTransaction(2022, 2, 10, "400"),
+	}, []io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 1, 1, "0.03"),
+	})
+	bank.AddFees(io.ScheduledFee{Monthly: true, Start: first, Amount: mustBigRatFromString("29")})
+	bank.SetMinimumPayments([]io.PlannedPayment{
+		{From: first, Day: 10, Amount: mustBigRatFromString("1000")},
+	})
+	bank.SetPenaltyInterest([]io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 1, 1, "0.02"),
+	}, big.NewRat(8, 100))
+
+	principal := mustBigRatFromString("10000")
+	whole := mustSeries(t, &bank, NewLoan(principal), first, last)
+
+	borrowers := []io.Borrower{
+		{Name: "A", Share: mustBigRatFromString("0.25")},
+		{Name: "B", Share: mustBigRatFromString("0.75")},
+	}
+	loans, err := splitBorrowers(bank, principal, first, last, borrowers, nil)
+	if err != nil {
+		t.Fatalf("splitting loan: %s", err)
+	}
+
+	for d, day := range whole {
+		fees, overdue, penalty := new(big.Rat), new(big.Rat), new(big.Rat)
+		for _, l := range loans {
+			fees.Add(fees, l.days[d].Loan.fees)
+			overdue.Add(overdue, l.days[d].Loan.overdue)
+			penalty.Add(penalty, l.days[d].Loan.penalty)
+		}
+
+		if want, got := day.Loan.fees, fees; want.Cmp(got) != 0 {
+			t.Errorf("%s: want fees %s, but got %s", day.Date, want.FloatString(2), got.FloatString(2))
+		}
+		if want, got := day.Loan.overdue, overdue; want.Cmp(got) != 0 {
+			t.Errorf("%s: want overdue %s, but got %s", day.Date, want.FloatString(2), got.FloatString(2))
+		}
+		if want, got := day.Loan.penalty, penalty; want.Cmp(got) != 0 {
+			t.Errorf("%s: want penalty interest %s, but got %s", day.Date, want.FloatString(2), got.FloatString(2))
+		}
+	}
+
+	if whole[len(whole)-1].Loan.overdue.Sign() == 0 {
+		t.Error("want an overdue amount on the last day")
+	}
+}
+
+func TestRunBorrowers(t *testing.T) {
+	transactions, err := io.ReadTransactions(path.Join("..", "testdata", "transactions.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading transactions: %s", err)
+	}
+
+	rates, err := io.ReadInterestRates(path.Join("..", "testdata", "annual_interest_rates.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading interest rates: %s", err)
+	}
+
+	borrowers, err := io.ReadBorrowers(path.Join("..", "testdata", "borrowers.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading borrowers: %s", err)
+	}
+
+	firstDay := time.Date(2022, time.June, 7, 0, 0, 0, 0, time.UTC)
+	principal := mustBigRatFromString("100000")
+
+	var outCSV bytes.Buffer
+	if err := RunBorrowers(&outCSV, firstDay, principal, NewBank(transactions, rates), borrowers, nil, ','); err != nil {
+		t.Fatalf("running borrowers: %s", err)
+	}
+
+	records, err := csv.NewReader(&outCSV).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV output: %s", err)
+	}
+
+	for _, record := range records[1:] {
+		if record[0] != "2022-11-22" {
+			continue
+		}
+
+		// The borrowers' balances add up to the balance of the whole
+		// loan (92173.64). The refund (ÅTERBETALN) reduces them but is
+		// not counted as a payment.
+		if want, got := []string{"3003.90", "46778.18", "4400.00", "45395.46"},
+			[]string{record[1], record[3], record[5], record[7]}; !equalStrings(want, got) {
+			t.Errorf("want payments and balances %v, but got %v", want, got)
+		}
+	}
+
+	var statementCSV bytes.Buffer
+	if err := BorrowerStatement(&statementCSV, firstDay, principal, NewBank(transactions, rates), borrowers, nil, 2022, ','); err != nil {
+		t.Fatalf("writing statement: %s", err)
+	}
+
+	statement, err := csv.NewReader(&statementCSV).ReadAll()
+	if err != nil {
+		t.Fatalf("reading statement CSV output: %s", err)
+	}
+
+	if want, got := 3, len(statement); want != got {
+		t.Fatalf("want %d records, but got %d", want, got)
+	}
+
+	t.Logf("%v", statement)
+
+	if want, got := "3003.90", statement[1][3]; want != got {
+		t.Errorf("want payments %s, but got %s", want, got)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -1,6 +1,7 @@
 package calc
 
 import (
+	"context"
 	"encoding/csv"
 	"io"
 	"math/big"
@@ -11,47 +12,232 @@ import (
 )
 
 // Run runs the calculations given the principal (i.e. initial sum of
-// money borrowed), the first day of the loan, a list of transactions
-// made, and a list of interest rate changes (incl. one that covers
-// the first day of the loan). Results are written to w as CSV
+// money borrowed), the first day of the loan, and a bank holding the
+// transactions made and the interest rate changes (incl. one that
+// covers the first day of the loan). Results are written to w as CSV
 // records—one record per day—indicating the state of the loan on each
-// day.
-func Run(w io.Writer, firstDay time.Time, principal *big.Rat, interestRates []intio.AnnualInterestRate, transactions []intio.Transaction, outComma rune) {
-	bank := NewBank(transactions, interestRates)
-	loan := NewLoan(principal)
+// day. Each record is written as soon as its day is processed.
+//
+// An error is returned if the loan cannot be processed (see
+// [Bank.Process]), as a [*WriteError] if the results cannot be
+// written, or if ctx is done before all days have been processed.
+func Run(ctx context.Context, w io.Writer, firstDay time.Time, principal *big.Rat, bank Bank, outComma rune) error {
+	_, err := RunFrom(ctx, w, firstDay, principal, bank, nil, outComma)
+	return err
+}
+
+// DayWriter writes days as CSV records, preceded by a header record,
+// indicating the state of the loan on each day. Besides the date, the
+// interest rate, the balance, the accrued interest, the surplus, and
+// whether the loan was paid off, the unpaid fees are only written for
+// a bank with scheduled fees or fee transactions, and the overdue amount and the unpaid penalty
+// interest for one with minimum payments or penalty interest.
+type DayWriter struct {
+	writer *csv.Writer
+	header bool
 
-	start := DateFromTime(firstDay)
-	end := DateFromTime(time.Now()).AddDate(0, 0, 1)
+	fees, late bool
+}
 
+// NewDayWriter returns a DayWriter that writes the days of bank to w
+// with the given field delimiter.
+func NewDayWriter(w io.Writer, bank *Bank, comma rune) *DayWriter {
 	writer := csv.NewWriter(w)
-	writer.Comma = outComma
+	writer.Comma = comma
+	return &DayWriter{
+		writer: writer,
+		fees:   len(bank.fees) > 0 || hasFeeTransactions(bank.transactions),
+		late:   len(bank.minimumPayments) > 0 || len(bank.penaltyRates) > 0,
+	}
+}
 
-	defer writer.Flush()
+// hasFeeTransactions reports whether any of transactions is a fee.
+func hasFeeTransactions(transactions []intio.Transaction) bool {
+	for _, t := range transactions {
+		if t.Kind == intio.Fee {
+			return true
+		}
+	}
+	return false
+}
+
+// Write writes the record of day, and the header record before the
+// first one. Records are buffered until [DayWriter.Flush] is called,
+// but any error from writing earlier ones is returned.
+func (dw *DayWriter) Write(day Day) error {
+	dw.writeHeader()
+
+	airText := "-"
+	if day.Rate != nil {
+		airText = new(big.Rat).Mul(day.Rate, big.NewRat(100, 1)).FloatString(2)
+	}
 
-	writer.Write([]string{
+	record := []string{
+		day.Date.Format(internal.DateLayout),
+		airText,
+		day.Loan.balance.FloatString(2),
+		day.Loan.interest.FloatString(2),
+	}
+	if dw.fees {
+		record = append(record, day.Loan.fees.FloatString(2))
+	}
+	if dw.late {
+		record = append(record, day.Loan.overdue.FloatString(2), day.Loan.penalty.FloatString(2))
+	}
+
+	paidOffText := ""
+	if day.PaidOff {
+		paidOffText = "yes"
+	}
+	record = append(record, new(big.Rat).Add(day.Loan.surplus, day.Loan.credit).FloatString(2), paidOffText)
+
+	dw.writer.Write(record)
+
+	if err := dw.writer.Error(); err != nil {
+		return &WriteError{Err: err}
+	}
+	return nil
+}
+
+// writeHeader writes the header record unless it has been written.
+func (dw *DayWriter) writeHeader() {
+	if dw.header {
+		return
+	}
+
+	header := []string{
 		"Date",
 		"Annual interest rate (%)",
 		"Balance",
 		"Accrued interest",
+	}
+	if dw.fees {
+		header = append(header, "Unpaid fees")
+	}
+	if dw.late {
+		header = append(header, "Overdue", "Unpaid penalty interest")
+	}
+	header = append(header, "Surplus", "Paid off")
+
+	dw.writer.Write(header)
+	dw.header = true
+}
+
+// Flush writes any buffered records, and the header record if no day
+// has been written, and returns a [*WriteError] if they cannot be
+// written.
+func (dw *DayWriter) Flush() error {
+	dw.writeHeader()
+	return flush(dw.writer)
+}
+
+// Day is the state of a loan at the end of a calendar day.
+type Day struct {
+	Date time.Time
+	// Rate is the annual interest rate in effect on Date, or nil if
+	// no rate covers the day.
+	Rate *big.Rat
+	// Interest is the interest accrued during the day.
+	Interest *big.Rat
+	// Fees is the sum of the fees charged during the day.
+	Fees *big.Rat
+	// PenaltyInterest is the penalty interest accrued during the day.
+	PenaltyInterest *big.Rat
+	// CreditInterest is the interest accrued on the surplus during
+	// the day.
+	CreditInterest *big.Rat
+	// Due is the minimum payment due on Date, or nil if none is due.
+	Due *Due
+	// PaidOff is true if the loan was paid off during the day.
+	PaidOff bool
+	// Transactions are the transactions applied during the day. They
+	// must not be modified.
+	Transactions []intio.Transaction
+	Loan         Loan
+}
+
+// Series processes loan with bank one day at a time, from the first
+// through the last day (both inclusive), and returns the state at
+// the end of each day. Processing stops at the first day that cannot
+// be processed (see [Bank.Process]), with an error.
+func Series(bank *Bank, loan Loan, first, last time.Time) ([]Day, error) {
+	var days []Day
+
+	err := Walk(context.Background(), bank, loan, first, last, func(d Day) error {
+		days = append(days, d)
+		return nil
 	})
 
-	for day := DateFromTime(start); day.Before(end); day = day.AddDate(0, 0, 1) {
-		loan = bank.Process(day, loan)
+	return days, err
+}
+
+// Walk processes loan with bank one day at a time, from the first
+// through the last day (both inclusive), and calls fn with the state
+// at the end of each day as soon as it has been processed. Walk stops
+// at the first day that cannot be processed (see [Bank.Process]), at
+// the first error returned by fn, or when ctx is done, and returns the
+// error.
+func Walk(ctx context.Context, bank *Bank, loan Loan, first, last time.Time, fn func(Day) error) error {
+	start := DateFromTime(first)
+	end := DateFromTime(last).AddDate(0, 0, 1)
+
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 
-		air, ok := bank.annualInterestRate(day)
+		d, err := step(bank, day, loan)
+		if err != nil {
+			return err
+		}
+		loan = d.Loan
 
-		airText := "-"
-		if ok {
-			airText = air.Mul(air, big.NewRat(100, 1)).FloatString(2)
+		if err := fn(d); err != nil {
+			return err
 		}
+	}
+
+	return nil
+}
+
+// step processes loan with bank on day and returns the state at the
+// end of the day.
+func step(bank *Bank, day time.Time, loan Loan) (Day, error) {
+	fees := bank.feesAmount(day)
 
-		writer.Write([]string{
-			day.Format(internal.DateLayout),
-			airText,
-			loan.balance.FloatString(2),
-			loan.interest.FloatString(2),
-		})
+	interest := loan.owed()
+	interest.Sub(interest, bank.transactionsAmount(day))
+	interest.Add(interest, fees)
+
+	paidOff := loan.owed().Sign() > 0
+
+	loan, a, err := bank.process(day, loan)
+	if err != nil {
+		return Day{}, err
+	}
+	interest.Sub(loan.owed(), interest)
+	interest.Sub(interest, a.penalty)
+	interest.Add(interest, a.credit)
+
+	paidOff = paidOff && loan.owed().Sign() <= 0
+
+	var rate *big.Rat
+	if air, ok := bank.annualInterestRate(day); ok {
+		rate = air
 	}
+
+	return Day{
+		Date:            day,
+		Rate:            rate,
+		Interest:        interest,
+		Fees:            fees,
+		PenaltyInterest: a.penalty,
+		CreditInterest:  a.credit,
+		Due:             a.due,
+		PaidOff:         paidOff,
+		Transactions:    bank.transactionsOn(day),
+		Loan:            loan,
+	}, nil
 }
 
 func DateFromTime(t time.Time) time.Time {
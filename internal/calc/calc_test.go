@@ -2,7 +2,9 @@ package calc
 
 import (
 	"bytes"
+	"context"
 	"encoding/csv"
+	"errors"
 	"math/big"
 	"path"
 	"testing"
@@ -40,7 +42,9 @@ func TestRun(t *testing.T) {
 	var outCSV bytes.Buffer
 	csvReader := csv.NewReader(&outCSV)
 
-	Run(&outCSV, firstDay, principal, interestRates, transactions, csvReader.Comma)
+	if err := Run(context.Background(), &outCSV, firstDay, principal, NewBank(transactions, interestRates), csvReader.Comma); err != nil {
+		t.Fatalf("running calculations: %s", err)
+	}
 
 	records, err := csvReader.ReadAll()
 	if err != nil {
@@ -106,3 +110,228 @@ func mustParseTime(t *testing.T, value string) time.Time {
 	}
 	return res
 }
+
+// mustSeries is like Series but fails the test on error.
+func mustSeries(t *testing.T, bank *Bank, loan Loan, first, last time.Time) []Day {
+	t.Helper()
+
+	days, err := Series(bank, loan, first, last)
+	if err != nil {
+		t.Fatalf("processing loan: %s", err)
+	}
+	return days
+}
+
+// mustProject is like Project but fails the test on error.
+func mustProject(t *testing.T, bank Bank, loan Loan, first, last time.Time, plan []io.PlannedPayment) ([]Day, Bank) {
+	t.Helper()
+
+	days, b, err := Project(bank, loan, first, last, plan)
+	if err != nil {
+		t.Fatalf("projecting loan: %s", err)
+	}
+	return days, b
+}
+
+func TestSeries_RateNotFound(t *testing.T) {
+	bank := NewBank(nil, []io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 1, 10, "0.03"),
+	})
+
+	days, err := Series(&bank, NewLoan(mustBigRatFromString("1000")),
+		time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2022, 1, 31, 0, 0, 0, 0, time.UTC))
+
+	var notFound *RateNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("want rate not found error, but got %v", err)
+	}
+	if want, got := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), notFound.Day; !want.Equal(got) {
+		t.Errorf("want missing rate on %s, but got %s", want, got)
+	}
+	if len(days) != 0 {
+		t.Errorf("want no days, but got %d", len(days))
+	}
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestRun_WriteError(t *testing.T) {
+	bank := NewBank(nil, []io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 1, 1, "0.03"),
+	})
+
+	err := Run(context.Background(), failingWriter{}, DateFromTime(time.Now()), mustBigRatFromString("1000"), bank, ';')
+
+	var writeErr *WriteError
+	if !errors.As(err, &writeErr) {
+		t.Fatalf("want write error, but got %v", err)
+	}
+}
+
+func TestDayWriter_Columns(t *testing.T) {
+	rates := []io.AnnualInterestRate{io.MustNewAnnualInterestRate(2022, 1, 1, "0.03")}
+
+	plain := NewBank(nil, rates)
+
+	withFees := NewBank(nil, rates)
+	withFees.AddFees(io.ScheduledFee{Monthly: true, Start: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Amount: mustBigRatFromString("29")})
+
+	fee := io.MustNewTransaction(2022, 1, 15, "25")
+	fee.Kind = io.Fee
+	withFeeTransaction := NewBank([]io.Transaction{fee}, rates)
+
+	late := NewBank(nil, rates)
+	late.SetPenaltyInterest(rates, big.NewRat(8, 100))
+
+	tests := []struct {
+		name string
+		bank Bank
+		want string
+	}{
+		{"plain", plain, "Date;Annual interest rate (%);Balance;Accrued interest;Surplus;Paid off\n"},
+		{"fees", withFees, "Date;Annual interest rate (%);Balance;Accrued interest;Unpaid fees;Surplus;Paid off\n"},
+		{"fee transaction", withFeeTransaction, "Date;Annual interest rate (%);Balance;Accrued interest;Unpaid fees;Surplus;Paid off\n"},
+		{"late", late, "Date;Annual interest rate (%);Balance;Accrued interest;Overdue;Unpaid penalty interest;Surplus;Paid off\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := NewDayWriter(&out, &tt.bank, ';').Flush(); err != nil {
+				t.Fatalf("writing header: %s", err)
+			}
+
+			if want, got := tt.want, out.String(); want != got {
+				t.Errorf("want header %q, but got %q", want, got)
+			}
+		})
+	}
+}
+
+func TestRun_FeeTransaction(t *testing.T) {
+	fee := io.MustNewTransaction(2022, 1, 15, "25")
+	fee.Kind = io.Fee
+	bank := NewBank([]io.Transaction{fee}, []io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 1, 1, "0"),
+	})
+
+	var out bytes.Buffer
+	if err := Run(context.Background(), &out, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), mustBigRatFromString("1000"), bank, ';'); err != nil {
+		t.Fatalf("running calculations: %s", err)
+	}
+
+	r := csv.NewReader(&out)
+	r.Comma = ';'
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV output: %s", err)
+	}
+
+	if want, got := "Unpaid fees", records[0][4]; want != got {
+		t.Fatalf("want column %q, but got %q", want, got)
+	}
+	if want, got := "25.00", records[15][4]; want != got {
+		t.Errorf("want unpaid fees %s on %s, but got %s", want, records[15][0], got)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	bank := NewBank([]io.Transaction{
+		io.MustNewTransaction(2022, 1, 3, "100"),
+		io.MustNewTransaction(2022, 1, 3, "50"),
+	}, []io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 1, 1, "0.03"),
+	})
+
+	first := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	var walked []Day
+	err := Walk(context.Background(), &bank, NewLoan(mustBigRatFromString("1000")), first, last, func(d Day) error {
+		walked = append(walked, d)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("walking loan: %s", err)
+	}
+
+	days := mustSeries(t, &bank, NewLoan(mustBigRatFromString("1000")), first, last)
+	if want, got := len(days), len(walked); want != got {
+		t.Fatalf("want %d days, but got %d", want, got)
+	}
+	for i := range days {
+		if want, got := days[i].Loan.owed(), walked[i].Loan.owed(); want.Cmp(got) != 0 {
+			t.Errorf("%s: want owed %s, but got %s", days[i].Date, want, got)
+		}
+	}
+
+	if want, got := 2, len(walked[2].Transactions); want != got {
+		t.Errorf("want %d transactions on %s, but got %d", want, walked[2].Date, got)
+	}
+	if want, got := 0, len(walked[3].Transactions); want != got {
+		t.Errorf("want %d transactions on %s, but got %d", want, walked[3].Date, got)
+	}
+}
+
+func TestWalk_Stop(t *testing.T) {
+	bank := NewBank(nil, []io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 1, 1, "0.03"),
+	})
+
+	first := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC)
+	errStop := errors.New("stop")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	tests := []struct {
+		name    string
+		fn      func(Day) error
+		wantErr error
+	}{
+		{
+			name: "callback error",
+			fn: func(d Day) error {
+				if d.Date.Day() == 5 {
+					return errStop
+				}
+				return nil
+			},
+			wantErr: errStop,
+		},
+		{
+			name: "context canceled",
+			fn: func(d Day) error {
+				if d.Date.Day() == 5 {
+					cancel()
+				}
+				return nil
+			},
+			wantErr: context.Canceled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := 0
+			err := Walk(ctx, &bank, NewLoan(mustBigRatFromString("1000")), first, last, func(d Day) error {
+				n++
+				return tt.fn(d)
+			})
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("want error %v, but got %v", tt.wantErr, err)
+			}
+			if want := 5; want != n {
+				t.Errorf("want %d days, but got %d", want, n)
+			}
+		})
+	}
+}
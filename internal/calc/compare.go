@@ -0,0 +1,173 @@
+package calc
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+	intio "gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// Scenario is one way of repaying a loan, e.g. with or without extra
+// payments.
+type Scenario struct {
+	Name string
+	// Bank holds the transactions, including any future extra
+	// payments, and the interest rates of the scenario.
+	Bank Bank
+	// Plan is the payment plan followed from tomorrow on, if any.
+	Plan []intio.PlannedPayment
+}
+
+// days processes a loan of principal according to s from the first
+// through the last day: until today as recorded by the bank, and from
+// tomorrow on as projected with the payment plan. Days after the loan
+// is paid off are left out.
+func (s Scenario) days(principal *big.Rat, first, last time.Time) ([]Day, error) {
+	bank := s.Bank
+	today := DateFromTime(time.Now())
+
+	end := DateFromTime(last)
+	if today.Before(end) {
+		end = today
+	}
+
+	days, err := Series(&bank, NewLoan(principal), first, end)
+	if err != nil {
+		return nil, err
+	}
+
+	start := end.AddDate(0, 0, 1)
+	if len(days) == 0 {
+		start = DateFromTime(first)
+	}
+	if start.After(DateFromTime(last)) {
+		return days, nil
+	}
+
+	loan := NewLoan(principal)
+	if len(days) > 0 {
+		loan = days[len(days)-1].Loan
+	}
+
+	projected, _, err := Project(bank, loan, start, last, s.Plan)
+	if err != nil {
+		return nil, err
+	}
+	return append(days, projected...), nil
+}
+
+// cost returns the cost of the loan during day, i.e. the interest,
+// fees, and penalty interest less any credit interest.
+func (d Day) cost() *big.Rat {
+	cost := new(big.Rat).Add(d.Interest, d.Fees)
+	cost.Add(cost, d.PenaltyInterest)
+	return cost.Sub(cost, d.CreditInterest)
+}
+
+// Comparison is the outcome of comparing two scenarios.
+type Comparison struct {
+	// Costs are the costs of the scenarios (interest, fees, and
+	// penalty interest less credit interest) over the whole horizon.
+	Costs [2]*big.Rat
+	// Cheaper is the index of the cheaper scenario over the whole
+	// horizon, or -1 if they cost the same.
+	Cheaper int
+	// BreakEven is the first day from which the cumulative cost of the
+	// cheaper scenario stays below that of the other one.
+	BreakEven time.Time
+}
+
+// RunCompare runs two scenarios for a loan of principal from the first
+// day through the last one and writes them side by side to w as CSV
+// records—one record per day—with the balance and cumulative interest
+// of each scenario and the differences between them (the second
+// scenario less the first). A scenario that is paid off keeps a zero
+// balance. The returned comparison summarizes the costs.
+func RunCompare(w io.Writer, firstDay time.Time, principal *big.Rat, a, b Scenario, last time.Time, outComma rune) (Comparison, error) {
+	scenarios := [2]Scenario{a, b}
+
+	var days [2][]Day
+	for i, s := range scenarios {
+		var err error
+		days[i], err = s.days(principal, firstDay, last)
+		if err != nil {
+			return Comparison{}, fmt.Errorf("%s: %w", s.Name, err)
+		}
+		if len(days[i]) == 0 {
+			return Comparison{}, fmt.Errorf("loan starts after the last day")
+		}
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = outComma
+
+	writer.Write([]string{
+		"Date",
+		a.Name + " balance",
+		a.Name + " cumulative interest",
+		b.Name + " balance",
+		b.Name + " cumulative interest",
+		"Balance difference",
+		"Cumulative interest difference",
+	})
+
+	c := Comparison{Costs: [2]*big.Rat{new(big.Rat), new(big.Rat)}, Cheaper: -1}
+
+	var interest, balance [2]*big.Rat
+	for i := range scenarios {
+		interest[i], balance[i] = new(big.Rat), new(big.Rat)
+	}
+
+	n := len(days[0])
+	if len(days[1]) > n {
+		n = len(days[1])
+	}
+
+	cheaper := -1
+	for d := 0; d < n; d++ {
+		var date time.Time
+
+		for i := range scenarios {
+			if d >= len(days[i]) {
+				balance[i].SetInt64(0)
+				continue
+			}
+
+			day := days[i][d]
+			date = day.Date
+			balance[i].Set(day.Loan.balance)
+			interest[i].Add(interest[i], day.Interest)
+			c.Costs[i].Add(c.Costs[i], day.cost())
+		}
+
+		switch cmp := c.Costs[0].Cmp(c.Costs[1]); {
+		case cmp == 0:
+			cheaper = -1
+		case cmp < 0 && cheaper != 0:
+			cheaper, c.BreakEven = 0, date
+		case cmp > 0 && cheaper != 1:
+			cheaper, c.BreakEven = 1, date
+		}
+
+		writer.Write([]string{
+			date.Format(internal.DateLayout),
+			balance[0].FloatString(2),
+			interest[0].FloatString(2),
+			balance[1].FloatString(2),
+			interest[1].FloatString(2),
+			new(big.Rat).Sub(balance[1], balance[0]).FloatString(2),
+			new(big.Rat).Sub(interest[1], interest[0]).FloatString(2),
+		})
+	}
+
+	c.Cheaper = cheaper
+	if cheaper < 0 {
+		c.BreakEven = time.Time{}
+	}
+
+	return c, flush(writer)
+}
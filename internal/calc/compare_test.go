@@ -0,0 +1,63 @@
+package calc
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+func TestRunCompare(t *testing.T) {
+	// Start in the future so that both scenarios are projected.
+	first := DateFromTime(time.Now()).AddDate(1, 0, 0)
+	extra := first.AddDate(0, 0, 10)
+	last := first.AddDate(0, 0, 59)
+
+	rates := []io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 1, 1, "0.12"),
+	}
+
+	a := Scenario{Name: "save", Bank: NewBank(nil, rates)}
+	b := Scenario{Name: "pay", Bank: NewBank([]io.Transaction{
+		io.MustNewTransaction(extra.Year(), extra.Month(), extra.Day(), "1000"),
+	}, rates)}
+
+	var outCSV bytes.Buffer
+
+	c, err := RunCompare(&outCSV, first, mustBigRatFromString("10000"), a, b, last, ';')
+	if err != nil {
+		t.Fatalf("comparing: %s", err)
+	}
+
+	if want, got := 1, c.Cheaper; want != got {
+		t.Errorf("want scenario %d cheaper, but got %d", want, got)
+	}
+	if want, got := extra, c.BreakEven; !want.Equal(got) {
+		t.Errorf("want break-even on %s, but got %s", want, got)
+	}
+	if c.Costs[1].Cmp(c.Costs[0]) >= 0 {
+		t.Errorf("want extra payment to cost less, but got %s and %s", c.Costs[1], c.Costs[0])
+	}
+
+	r := csv.NewReader(&outCSV)
+	r.Comma = ';'
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV output: %s", err)
+	}
+
+	if want, got := 61, len(records); want != got {
+		t.Fatalf("want %d records, but got %d", want, got)
+	}
+
+	for _, record := range records[1:] {
+		if record[0] == extra.Format(internal.DateLayout) {
+			if want, got := "-1000.00", record[5]; want != got {
+				t.Errorf("want balance difference %s, but got %s", want, got)
+			}
+		}
+	}
+}
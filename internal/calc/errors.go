@@ -0,0 +1,49 @@
+package calc
+
+import (
+	"encoding/csv"
+	"fmt"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+)
+
+// RateNotFoundError is returned when no interest rate covers a day
+// that a loan is processed on.
+type RateNotFoundError struct {
+	Day time.Time
+	// Penalty is true if the missing rate is the penalty interest
+	// rate rather than the annual interest rate of the loan.
+	Penalty bool
+}
+
+func (e *RateNotFoundError) Error() string {
+	what := "annual interest rate"
+	if e.Penalty {
+		what = "penalty interest rate"
+	}
+	return fmt.Sprintf("%s not found for %s", what, e.Day.Format(internal.DateLayout))
+}
+
+// WriteError is returned when results cannot be written.
+type WriteError struct {
+	Err error
+}
+
+func (e *WriteError) Error() string {
+	return fmt.Sprintf("writing results: %s", e.Err)
+}
+
+func (e *WriteError) Unwrap() error {
+	return e.Err
+}
+
+// flush flushes writer and returns any error that occurred while
+// writing to it as a [WriteError].
+func flush(writer *csv.Writer) error {
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return &WriteError{Err: err}
+	}
+	return nil
+}
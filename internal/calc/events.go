@@ -0,0 +1,157 @@
+package calc
+
+import (
+	"math/big"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// SeriesAt processes loan with bank from the first through the last
+// day (both inclusive), like [Series], but returns the state at the end
+// of the given days only, and of the last day, in order. Days outside
+// the range are ignored.
+//
+// Rather than processing every day, SeriesAt jumps between events:
+// days with transactions, fees, or minimum payments due, days on which
+// an interest rate changes or interest is capitalized, and the given
+// days. The interest accrued during the quiet days in between is
+// calculated for the whole span at once, which gives the same result
+// as processing the days one at a time.
+func SeriesAt(bank *Bank, loan Loan, first, last time.Time, at []time.Time) ([]Day, error) {
+	start := DateFromTime(first)
+	end := DateFromTime(last)
+
+	outputs := map[time.Time]bool{end: true}
+	for _, t := range at {
+		outputs[DateFromTime(t)] = true
+	}
+
+	changes := bank.rateChanges()
+	event := func(day time.Time) bool {
+		return outputs[day] || changes[day] || bank.eventOn(day)
+	}
+
+	var days []Day
+
+	for day := start; !day.After(end); {
+		if day.Equal(start) || event(day) || !settled(loan) {
+			d, err := step(bank, day, loan)
+			if err != nil {
+				return days, err
+			}
+			loan = d.Loan
+			if outputs[day] {
+				days = append(days, d)
+			}
+			day = day.AddDate(0, 0, 1)
+			continue
+		}
+
+		next := day.AddDate(0, 0, 1)
+		for !next.After(end) && !event(next) {
+			next = next.AddDate(0, 0, 1)
+		}
+
+		var err error
+		loan, err = bank.accrue(day, int64(next.Sub(day).Hours()/24), loan)
+		if err != nil {
+			return days, err
+		}
+		day = next
+	}
+
+	return days, nil
+}
+
+// eventOn reports whether day is the first of a month, or has
+// transactions, scheduled fees, or a minimum payment due, i.e. whether
+// processing it may do more than accrue interest. Interest rate
+// changes are found by rateChanges.
+func (b *Bank) eventOn(day time.Time) bool {
+	if day.Day() == 1 || len(b.transactionsOn(day)) > 0 {
+		return true
+	}
+
+	for _, f := range b.fees {
+		if f.ChargedOn(day) {
+			return true
+		}
+	}
+
+	if len(b.minimumPayments) > 0 {
+		if entry, ok := io.PlanOn(b.minimumPayments, day); ok && entry.DueOn(day) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// rateChanges returns the days on which the interest, penalty, or
+// deposit rate may change, including the first and the day after the
+// last day of each fixed-rate period.
+func (b *Bank) rateChanges() map[time.Time]bool {
+	changes := make(map[time.Time]bool)
+
+	for _, rates := range [][]io.AnnualInterestRate{b.interestRates, b.penaltyRates, b.depositRates} {
+		for _, r := range rates {
+			changes[DateFromTime(r.Day)] = true
+		}
+	}
+
+	for _, p := range b.fixedRatePeriods {
+		changes[DateFromTime(p.Start)] = true
+		changes[DateFromTime(p.End)] = true
+	}
+
+	return changes
+}
+
+// settled reports whether processing loan on a day without events
+// would only accrue interest, i.e. whether it has no negative balance
+// and no surplus left to settle what is owed with.
+func settled(loan Loan) bool {
+	if loan.balance.Sign() < 0 {
+		return false
+	}
+	if loan.surplus.Sign() <= 0 {
+		return true
+	}
+
+	for _, x := range []*big.Rat{loan.balance, loan.interest, loan.fees, loan.penalty, loan.overdue} {
+		if x.Sign() > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// accrue returns in with the interest, penalty interest, and credit
+// interest of n days without events, starting with day, accrued. The
+// days must be in the same month and have the same rates.
+func (b *Bank) accrue(day time.Time, n int64, in Loan) (Loan, error) {
+	rate, ok := b.annualInterestRate(day)
+	if !ok {
+		return in, &RateNotFoundError{Day: DateFromTime(day)}
+	}
+
+	penalty, err := b.penaltyInterest(day, in.overdue)
+	if err != nil {
+		return in, err
+	}
+
+	days := big.NewRat(n, 1)
+	out := CopyLoan(in)
+
+	y, m, _ := day.Date()
+	interest := new(big.Rat).Mul(annualToDaily(rate, daysInMonth(m, y)), out.balance)
+	out.interest.Add(out.interest, interest.Mul(interest, days))
+
+	out.penalty.Add(out.penalty, penalty.Mul(penalty, days))
+
+	credit := b.creditInterest(day, out.surplus)
+	out.credit.Add(out.credit, credit.Mul(credit, days))
+
+	return out, nil
+}
@@ -0,0 +1,182 @@
+package calc
+
+import (
+	"errors"
+	"math/big"
+	"path"
+	"testing"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// TestSeriesAt_Differential checks that SeriesAt gives the same days
+// as Series, both when every day is asked for and when only a few are.
+func TestSeriesAt_Differential(t *testing.T) {
+	transactions, err := io.ReadTransactions(path.Join("..", "testdata", "transactions.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading transactions: %s", err)
+	}
+	rates, err := io.ReadInterestRates(path.Join("..", "testdata", "annual_interest_rates.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading interest rates: %s", err)
+	}
+	fees, err := io.ReadFees(path.Join("..", "testdata", "fees.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading fees: %s", err)
+	}
+	periods, err := io.ReadFixedRatePeriods(path.Join("..", "testdata", "fixed_rate_periods.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading fixed-rate periods: %s", err)
+	}
+
+	withFees := NewBank(transactions, rates)
+	withFees.AddFees(fees...)
+	withFees.AddFixedRatePeriods(periods...)
+	withFees.SetMinimumPayments([]io.PlannedPayment{
+		{From: time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC), Day: 28, Amount: mustBigRatFromString("1500")},
+	})
+	withFees.SetPenaltyInterest(rates, big.NewRat(8, 100))
+
+	tests := []struct {
+		name        string
+		bank        Bank
+		principal   string
+		first, last time.Time
+	}{
+		{
+			name:      "fees, fixed rate and penalty interest",
+			bank:      withFees,
+			principal: "100000",
+			first:     time.Date(2022, 6, 7, 0, 0, 0, 0, time.UTC),
+			last:      time.Date(2023, 6, 30, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "overpayment credit",
+			bank:      overpaidBank(OverpaymentCredit),
+			principal: "1000",
+			first:     time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			last:      time.Date(2022, 3, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:      "overpayment surplus",
+			bank:      overpaidBank(OverpaymentSurplus),
+			principal: "1000",
+			first:     time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+			last:      time.Date(2022, 3, 31, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loan := NewLoan(mustBigRatFromString(tt.principal))
+			want := mustSeries(t, &tt.bank, loan, tt.first, tt.last)
+
+			every := make([]time.Time, len(want))
+			for i, d := range want {
+				every[i] = d.Date
+			}
+
+			got, err := SeriesAt(&tt.bank, loan, tt.first, tt.last, every)
+			if err != nil {
+				t.Fatalf("processing every day: %s", err)
+			}
+			if len(want) != len(got) {
+				t.Fatalf("want %d days, but got %d", len(want), len(got))
+			}
+			for i := range want {
+				compareDays(t, want[i], got[i])
+			}
+
+			// Every tenth day, plus the last day.
+			var sparse []time.Time
+			for i := 0; i < len(want); i += 10 {
+				sparse = append(sparse, want[i].Date)
+			}
+
+			got, err = SeriesAt(&tt.bank, loan, tt.first, tt.last, sparse)
+			if err != nil {
+				t.Fatalf("processing every tenth day: %s", err)
+			}
+			wantLen := len(sparse)
+			if !sparse[len(sparse)-1].Equal(tt.last) {
+				wantLen++
+			}
+			if wantLen != len(got) {
+				t.Fatalf("want %d days, but got %d", wantLen, len(got))
+			}
+			for _, d := range got {
+				w, _ := dayOf(want, d.Date)
+				compareDays(t, w, d)
+			}
+			if !got[len(got)-1].Date.Equal(tt.last) {
+				t.Errorf("want last day %s, but got %s", tt.last, got[len(got)-1].Date)
+			}
+		})
+	}
+}
+
+func TestSeriesAt_RateNotFound(t *testing.T) {
+	// The penalty rates start after the first minimum payment is
+	// missed.
+	bank := NewBank(nil, []io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 1, 1, "0.02"),
+	})
+	bank.SetMinimumPayments([]io.PlannedPayment{
+		{From: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Day: 10, Amount: mustBigRatFromString("100")},
+	})
+	bank.SetPenaltyInterest([]io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 2, 1, "0.02"),
+	}, big.NewRat(8, 100))
+
+	first := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(2022, 1, 31, 0, 0, 0, 0, time.UTC)
+
+	_, want := Series(&bank, NewLoan(big.NewRat(1000, 1)), first, last)
+	_, got := SeriesAt(&bank, NewLoan(big.NewRat(1000, 1)), first, last, nil)
+
+	var wantErr, gotErr *RateNotFoundError
+	if !errors.As(want, &wantErr) || !errors.As(got, &gotErr) {
+		t.Fatalf("want rate not found errors, but got %v and %v", want, got)
+	}
+	if !wantErr.Day.Equal(gotErr.Day) || !gotErr.Penalty {
+		t.Errorf("want error %v, but got %v", wantErr, gotErr)
+	}
+}
+
+func compareDays(t *testing.T, want, got Day) {
+	t.Helper()
+
+	if !want.Date.Equal(got.Date) {
+		t.Fatalf("want day %s, but got %s", want.Date, got.Date)
+	}
+
+	for _, x := range []struct {
+		name      string
+		want, got *big.Rat
+	}{
+		{"balance", want.Loan.balance, got.Loan.balance},
+		{"interest", want.Loan.interest, got.Loan.interest},
+		{"fees", want.Loan.fees, got.Loan.fees},
+		{"overdue", want.Loan.overdue, got.Loan.overdue},
+		{"paid", want.Loan.paid, got.Loan.paid},
+		{"penalty", want.Loan.penalty, got.Loan.penalty},
+		{"surplus", want.Loan.surplus, got.Loan.surplus},
+		{"credit", want.Loan.credit, got.Loan.credit},
+		{"interest of the day", want.Interest, got.Interest},
+		{"fees of the day", want.Fees, got.Fees},
+		{"penalty interest of the day", want.PenaltyInterest, got.PenaltyInterest},
+		{"credit interest of the day", want.CreditInterest, got.CreditInterest},
+	} {
+		if x.want.Cmp(x.got) != 0 {
+			t.Errorf("%s: want %s %s, but got %s", want.Date.Format("2006-01-02"), x.name, x.want, x.got)
+		}
+	}
+
+	if want.PaidOff != got.PaidOff {
+		t.Errorf("%s: want paid off %t, but got %t", want.Date.Format("2006-01-02"), want.PaidOff, got.PaidOff)
+	}
+	if (want.Due == nil) != (got.Due == nil) {
+		t.Errorf("%s: want due %v, but got %v", want.Date.Format("2006-01-02"), want.Due, got.Due)
+	}
+}
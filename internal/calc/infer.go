@@ -0,0 +1,132 @@
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// inferredRateUnit is the precision of inferred annual interest rates
+// expressed as a decimal, i.e. one ten-thousandth of a percent.
+const inferredRateUnit = 1_000_000
+
+// Bounds of inferred annual interest rates in units of
+// inferredRateUnit.
+const (
+	minInferredRate = -inferredRateUnit / 10 // -10 %
+	maxInferredRate = inferredRateUnit       // 100 %
+)
+
+// ErrRateUndetermined is returned by InferRates when a checkpoint does
+// not depend on the interest rate, e.g. when only the balance is known
+// and no interest is capitalized before it.
+var ErrRateUndetermined = errors.New("checkpoint does not determine the interest rate")
+
+// InferRates infers the annual interest rates of a loan from observed
+// checkpoints. The interest rates in bank are ignored; its
+// transactions are applied to loan from the first day.
+//
+// The rate is assumed to be constant between consecutive checkpoints.
+// For each span, the rate (to the nearest 0.0001 %) that makes the
+// calculated balance plus accrued interest on the checkpoint day
+// closest to the reported one is found by bisection. If the
+// checkpoint lacks reported interest, only the balance is compared.
+// The returned rates take effect on the first day and on each day
+// after a checkpoint but the last.
+func InferRates(bank Bank, loan Loan, first time.Time, checkpoints []io.Checkpoint) ([]io.AnnualInterestRate, error) {
+	checkpoints = append([]io.Checkpoint(nil), checkpoints...)
+	sort.SliceStable(checkpoints, func(i, j int) bool {
+		return checkpoints[i].Day.Before(checkpoints[j].Day)
+	})
+
+	rates := []io.AnnualInterestRate{}
+	start := DateFromTime(first)
+
+	for _, c := range checkpoints {
+		day := DateFromTime(c.Day)
+		if day.Before(start) {
+			return nil, fmt.Errorf("checkpoint on %s is before %s",
+				day.Format(internal.DateLayout), start.Format(internal.DateLayout))
+		}
+
+		target := new(big.Rat).Set(c.Balance)
+		if c.Interest != nil {
+			target.Add(target, c.Interest)
+		}
+
+		var spanErr error
+		span := func(n int) (Loan, *big.Rat) {
+			b := bank.withInterestRates(append(rates[:len(rates):len(rates)], io.AnnualInterestRate{
+				Day:         start,
+				DecimalRate: big.NewRat(int64(n), inferredRateUnit),
+			}))
+			days, err := Series(&b, loan, start, day)
+			if err != nil {
+				spanErr = err
+				return loan, new(big.Rat)
+			}
+			end := days[len(days)-1].Loan
+
+			got := new(big.Rat).Set(end.balance)
+			if c.Interest != nil {
+				got.Add(got, end.interest)
+			}
+			return end, got
+		}
+
+		_, lo := span(minInferredRate)
+		_, hi := span(maxInferredRate)
+		if spanErr != nil {
+			return nil, spanErr
+		}
+		if lo.Cmp(hi) == 0 {
+			return nil, fmt.Errorf("checkpoint on %s: %w", day.Format(internal.DateLayout), ErrRateUndetermined)
+		}
+		if target.Cmp(lo) < 0 || target.Cmp(hi) > 0 {
+			return nil, fmt.Errorf("checkpoint on %s: no rate between %s and %s %% explains it",
+				day.Format(internal.DateLayout),
+				big.NewRat(minInferredRate*100, inferredRateUnit).FloatString(0),
+				big.NewRat(maxInferredRate*100, inferredRateUnit).FloatString(0))
+		}
+
+		// Find the smallest rate whose result is not below target,
+		// then pick it or the one below, whichever comes closer.
+		n := minInferredRate + sort.Search(maxInferredRate-minInferredRate+1, func(i int) bool {
+			_, got := span(minInferredRate + i)
+			return got.Cmp(target) >= 0
+		})
+
+		end, got := span(n)
+		if n > minInferredRate {
+			belowEnd, below := span(n - 1)
+			if closer(below, got, target) {
+				n, end = n-1, belowEnd
+			}
+		}
+		if spanErr != nil {
+			return nil, spanErr
+		}
+
+		rates = append(rates, io.AnnualInterestRate{
+			Day:         start,
+			DecimalRate: big.NewRat(int64(n), inferredRateUnit),
+		})
+
+		loan = end
+		start = day.AddDate(0, 0, 1)
+	}
+
+	return rates, nil
+}
+
+// closer reports whether a is strictly closer to target than b.
+func closer(a, b, target *big.Rat) bool {
+	da := new(big.Rat).Sub(a, target)
+	db := new(big.Rat).Sub(b, target)
+	return da.Abs(da).Cmp(db.Abs(db)) < 0
+}
@@ -0,0 +1,77 @@
+package calc
+
+import (
+	"errors"
+	"path"
+	"testing"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+func TestInferRates(t *testing.T) {
+	transactions, err := io.ReadTransactions(path.Join("..", "testdata", "transactions.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading transactions: %s", err)
+	}
+
+	rates, err := io.ReadInterestRates(path.Join("..", "testdata", "annual_interest_rates.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading interest rates: %s", err)
+	}
+
+	bank := NewBank(transactions, rates)
+	loan := NewLoan(mustBigRatFromString("100000"))
+	firstDay := time.Date(2022, time.June, 7, 0, 0, 0, 0, time.UTC)
+	days := mustSeries(t, &bank, loan, firstDay, time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC))
+
+	checkpoints := []io.Checkpoint{}
+	for _, d := range []time.Time{
+		time.Date(2022, 7, 5, 0, 0, 0, 0, time.UTC),
+		time.Date(2022, 9, 20, 0, 0, 0, 0, time.UTC),
+		time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC),
+	} {
+		day, _ := dayOf(days, d)
+		checkpoints = append(checkpoints, io.Checkpoint{
+			Day:      d,
+			Balance:  day.Loan.balance,
+			Interest: day.Loan.interest,
+		})
+	}
+
+	got, err := InferRates(NewBank(transactions, nil), loan, firstDay, checkpoints)
+	if err != nil {
+		t.Fatalf("inferring rates: %s", err)
+	}
+
+	want := []io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, time.June, 7, "0.0114"),
+		io.MustNewAnnualInterestRate(2022, time.July, 6, "0.0164"),
+		io.MustNewAnnualInterestRate(2022, time.September, 21, "0.0264"),
+	}
+
+	if want, got := len(want), len(got); want != got {
+		t.Fatalf("length %d != %d", want, got)
+	}
+
+	for i, got := range got {
+		if !want[i].Equal(got) {
+			t.Errorf("i=%d, want %s, but got %s", i, want[i], got)
+		}
+	}
+}
+
+func TestInferRates_Undetermined(t *testing.T) {
+	bank := NewBank(nil, nil)
+	loan := NewLoan(mustBigRatFromString("100000"))
+	firstDay := time.Date(2022, time.June, 7, 0, 0, 0, 0, time.UTC)
+
+	_, err := InferRates(bank, loan, firstDay, []io.Checkpoint{{
+		Day:     time.Date(2022, 6, 30, 0, 0, 0, 0, time.UTC),
+		Balance: mustBigRatFromString("100000"),
+	}})
+
+	if !errors.Is(err, ErrRateUndetermined) {
+		t.Errorf("want %v, but got %v", ErrRateUndetermined, err)
+	}
+}
@@ -0,0 +1,159 @@
+package calc
+
+import (
+	"encoding/csv"
+	"io"
+	"math/big"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+	intio "gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// Due is a minimum payment that is due on a day.
+type Due struct {
+	Minimum *big.Rat
+	// Paid is the sum of the payments made towards Minimum since the
+	// previous due day, once any overdue payments were settled.
+	Paid *big.Rat
+	// Shortfall is the part of Minimum left unpaid, which is overdue
+	// from the due day on.
+	Shortfall *big.Rat
+}
+
+// Status returns "ok" if the minimum payment was paid in full,
+// "missed" if nothing was paid towards it, and "short" otherwise.
+func (d Due) Status() string {
+	switch {
+	case d.Shortfall.Sign() <= 0:
+		return "ok"
+	case d.Paid.Sign() <= 0:
+		return "missed"
+	default:
+		return "short"
+	}
+}
+
+// SetMinimumPayments sets the minimum payments required by the loan
+// agreement. Payments settle overdue payments first, and whatever is
+// left counts towards the next minimum payment. The part of a minimum
+// payment left unpaid on its due day becomes overdue.
+func (b *Bank) SetMinimumPayments(plan []intio.PlannedPayment) {
+	b.minimumPayments = append([]intio.PlannedPayment(nil), plan...)
+	intio.SortPlan(b.minimumPayments)
+}
+
+// SetPenaltyInterest makes overdue payments accrue penalty interest
+// at the reference rate plus addition, both in decimal form. Penalty
+// interest is tracked separately from the balance and the ordinary
+// interest.
+func (b *Bank) SetPenaltyInterest(reference []intio.AnnualInterestRate, addition *big.Rat) {
+	rates := sortedRates(reference)
+	for i, r := range rates {
+		rates[i].DecimalRate = new(big.Rat).Add(r.DecimalRate, addition)
+	}
+	b.penaltyRates = rates
+}
+
+// checkMinimumPayment settles overdue payments with the payments of
+// day, counts the rest towards the next minimum payment and, if one is
+// due on day, returns it and adds any shortfall to the overdue amount.
+func (b *Bank) checkMinimumPayment(day time.Time, loan *Loan) *Due {
+	if len(b.minimumPayments) == 0 {
+		return nil
+	}
+
+	paid := b.paymentsAmount(day)
+	settled := new(big.Rat).Set(loan.overdue)
+	if settled.Cmp(paid) > 0 {
+		settled.Set(paid)
+	}
+	loan.overdue.Sub(loan.overdue, settled)
+	loan.paid.Add(loan.paid, paid.Sub(paid, settled))
+
+	day = DateFromTime(day)
+	entry, ok := intio.PlanOn(b.minimumPayments, day)
+	if !ok || !entry.DueOn(day) {
+		return nil
+	}
+
+	due := &Due{
+		Minimum:   new(big.Rat).Set(entry.Amount),
+		Paid:      new(big.Rat).Set(loan.paid),
+		Shortfall: new(big.Rat).Sub(entry.Amount, loan.paid),
+	}
+	if due.Shortfall.Sign() < 0 {
+		due.Shortfall.SetInt64(0)
+	}
+
+	loan.overdue.Add(loan.overdue, due.Shortfall)
+	if loan.overdue.Cmp(loan.balance) > 0 {
+		// Nothing beyond the balance can be overdue.
+		loan.overdue.Set(loan.balance)
+		if loan.overdue.Sign() < 0 {
+			loan.overdue.SetInt64(0)
+		}
+	}
+	loan.paid.SetInt64(0)
+
+	return due
+}
+
+// penaltyInterest returns the penalty interest accrued on overdue
+// during day.
+func (b *Bank) penaltyInterest(day time.Time, overdue *big.Rat) (*big.Rat, error) {
+	if b.penaltyRates == nil || overdue.Sign() <= 0 {
+		return new(big.Rat), nil
+	}
+
+	rate, ok := rateOn(b.penaltyRates, day)
+	if !ok {
+		return nil, &RateNotFoundError{Day: DateFromTime(day), Penalty: true}
+	}
+
+	y, m, _ := day.Date()
+	return new(big.Rat).Mul(annualToDaily(rate, daysInMonth(m, y)), overdue), nil
+}
+
+// RunDues runs the calculations for a loan with minimum payments and
+// writes the outcome of each minimum payment due so far to w as CSV
+// records, with the amount paid towards it and the shortfall, if any,
+// as well as the overdue amount and unpaid penalty interest at the end
+// of the due day.
+func RunDues(w io.Writer, firstDay time.Time, principal *big.Rat, bank Bank, outComma rune) error {
+	days, err := Series(&bank, NewLoan(principal), firstDay, time.Now())
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = outComma
+
+	writer.Write([]string{
+		"Date",
+		"Minimum payment",
+		"Paid",
+		"Shortfall",
+		"Status",
+		"Overdue",
+		"Unpaid penalty interest",
+	})
+
+	for _, day := range days {
+		if day.Due == nil {
+			continue
+		}
+
+		writer.Write([]string{
+			day.Date.Format(internal.DateLayout),
+			day.Due.Minimum.FloatString(2),
+			day.Due.Paid.FloatString(2),
+			day.Due.Shortfall.FloatString(2),
+			day.Due.Status(),
+			day.Loan.overdue.FloatString(2),
+			day.Loan.penalty.FloatString(2),
+		})
+	}
+
+	return flush(writer)
+}
@@ -0,0 +1,87 @@
+package calc
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+func TestMinimumPayments(t *testing.T) {
+	bank := NewBank([]io.Transaction{
+		io.MustNewTransaction(2022, 1, 5, "1000"),
+		io.MustNewTransaction(2022, 2, 10, "400"),
+		io.MustNewTransaction(2022, 4, 5, "2000"),
+	}, []io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 1, 1, "0"),
+	})
+	bank.SetMinimumPayments([]io.PlannedPayment{
+		{From: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), Day: 10, Amount: mustBigRatFromString("1000")},
+	})
+	bank.SetPenaltyInterest([]io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 1, 1, "0"),
+	}, big.NewRat(8, 100))
+
+	days := mustSeries(t, &bank, NewLoan(mustBigRatFromString("10000")),
+		time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2022, 4, 30, 0, 0, 0, 0, time.UTC))
+
+	var dues []Day
+	for _, d := range days {
+		if d.Due != nil {
+			dues = append(dues, d)
+		}
+	}
+
+	tests := []struct {
+		status, shortfall, overdue string
+	}{
+		{"ok", "0", "0"},
+		{"short", "600", "600"},
+		// Nothing paid, so the whole minimum payment adds to the
+		// overdue amount.
+		{"missed", "1000", "1600"},
+		// The payment settles the overdue amount first, so only 400
+		// counts towards the minimum payment.
+		{"short", "600", "600"},
+	}
+
+	if want, got := len(tests), len(dues); want != got {
+		t.Fatalf("want %d due days, but got %d", want, got)
+	}
+
+	for i, tt := range tests {
+		d := dues[i]
+		if want, got := tt.status, d.Due.Status(); want != got {
+			t.Errorf("%s: want status %q, but got %q", d.Date, want, got)
+		}
+		if want, got := mustBigRatFromString(tt.shortfall), d.Due.Shortfall; want.Cmp(got) != 0 {
+			t.Errorf("%s: want shortfall %s, but got %s", d.Date, want, got)
+		}
+		if want, got := mustBigRatFromString(tt.overdue), d.Loan.overdue; want.Cmp(got) != 0 {
+			t.Errorf("%s: want overdue %s, but got %s", d.Date, want, got)
+		}
+	}
+
+	// 600 overdue at 8 % for one day of February.
+	if want, got := big.NewRat(600*8, 100*12*28), dues[1].PenaltyInterest; want.Cmp(got) != 0 {
+		t.Errorf("want penalty interest %s, but got %s", want, got)
+	}
+
+	// Penalty interest is kept out of the ordinary interest.
+	if got := dues[1].Interest; got.Sign() != 0 {
+		t.Errorf("want no interest at zero rate, but got %s", got)
+	}
+
+	// The payment in April settles the unpaid penalty interest before
+	// reducing the balance.
+	d, _ := dayOf(days, time.Date(2022, 4, 5, 0, 0, 0, 0, time.UTC))
+	april := d.Loan
+	if april.penalty.Sign() != 0 {
+		t.Errorf("want penalty interest settled, but got %s", april.penalty)
+	}
+	if paid := new(big.Rat).Sub(mustBigRatFromString("10000"), april.balance); paid.Cmp(mustBigRatFromString("3400")) >= 0 {
+		t.Errorf("want less than 3400 to reduce the balance, but got %s", paid.FloatString(2))
+	}
+}
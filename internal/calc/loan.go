@@ -10,17 +10,115 @@ type Loan struct {
 	// interest is the accrued interest over some period of time –
 	// typically a calendar month.
 	interest *big.Rat
+	// fees is the sum of unpaid fees. Fees do not accrue interest.
+	fees *big.Rat
+	// overdue is the part of the minimum payments due so far that
+	// has not been paid. It is part of the balance.
+	overdue *big.Rat
+	// paid is the sum of the payments made towards the next minimum
+	// payment.
+	paid *big.Rat
+	// penalty is the accrued and unpaid penalty interest
+	// (dröjsmålsränta) on overdue payments. Penalty interest does not
+	// accrue interest.
+	penalty *big.Rat
+	// surplus is the amount paid in excess of what was owed, including
+	// any credit interest capitalized on it.
+	surplus *big.Rat
+	// credit is the accrued credit interest on the surplus over some
+	// period of time – typically a calendar month.
+	credit *big.Rat
 }
 
 func NewLoan(principalBalance *big.Rat) Loan {
 	return Loan{
 		balance:  new(big.Rat).Set(principalBalance),
 		interest: new(big.Rat),
+		fees:     new(big.Rat),
+		overdue:  new(big.Rat),
+		paid:     new(big.Rat),
+		penalty:  new(big.Rat),
+		surplus:  new(big.Rat),
+		credit:   new(big.Rat),
 	}
 }
 
 func CopyLoan(loan Loan) Loan {
-	cpy := NewLoan(loan.balance)
-	cpy.interest.Set(loan.interest)
+	cpy := NewLoan(new(big.Rat))
+	for _, f := range []struct{ dst, src *big.Rat }{
+		{cpy.balance, loan.balance},
+		{cpy.interest, loan.interest},
+		{cpy.fees, loan.fees},
+		{cpy.overdue, loan.overdue},
+		{cpy.paid, loan.paid},
+		{cpy.penalty, loan.penalty},
+		{cpy.surplus, loan.surplus},
+		{cpy.credit, loan.credit},
+	} {
+		if f.src != nil {
+			f.dst.Set(f.src)
+		}
+	}
 	return cpy
 }
+
+// owed returns the total amount owed, i.e. the balance plus the
+// accrued interest, unpaid fees and penalty interest, less the surplus
+// and accrued credit interest. It is negative if the loan is paid off
+// with a surplus.
+func (l Loan) owed() *big.Rat {
+	owed := new(big.Rat).Add(l.balance, l.interest)
+	owed.Add(owed, l.fees)
+	owed.Add(owed, l.penalty)
+	owed.Sub(owed, l.surplus)
+	return owed.Sub(owed, l.credit)
+}
+
+// Balance returns the unpaid principal balance of the loan.
+func (l Loan) Balance() *big.Rat {
+	return copyOrZero(l.balance)
+}
+
+// Interest returns the interest accrued on the loan since it was last
+// capitalized.
+func (l Loan) Interest() *big.Rat {
+	return copyOrZero(l.interest)
+}
+
+// Fees returns the unpaid fees of the loan.
+func (l Loan) Fees() *big.Rat {
+	return copyOrZero(l.fees)
+}
+
+// Overdue returns the part of the minimum payments due so far that has
+// not been paid.
+func (l Loan) Overdue() *big.Rat {
+	return copyOrZero(l.overdue)
+}
+
+// PenaltyInterest returns the accrued and unpaid penalty interest of
+// the loan.
+func (l Loan) PenaltyInterest() *big.Rat {
+	return copyOrZero(l.penalty)
+}
+
+// Surplus returns the amount paid in excess of what was owed on the
+// loan, including the accrued credit interest.
+func (l Loan) Surplus() *big.Rat {
+	return new(big.Rat).Add(copyOrZero(l.surplus), copyOrZero(l.credit))
+}
+
+// Owed returns the total amount owed on the loan, i.e. the balance
+// plus the accrued interest, unpaid fees, and penalty interest, less
+// the surplus. It is negative if the loan is paid off with a surplus.
+func (l Loan) Owed() *big.Rat {
+	return CopyLoan(l).owed()
+}
+
+// copyOrZero returns a copy of x, or zero if x is nil.
+func copyOrZero(x *big.Rat) *big.Rat {
+	if x == nil {
+		return new(big.Rat)
+	}
+	return new(big.Rat).Set(x)
+}
@@ -1,8 +1,34 @@
 package calc
 
+import (
+	"testing"
+)
+
 // TODO: Test amortization done on the 1st (or 3rd -- whenever the rollover happens)
 
 // TODO: Test what happens if interest is paid a day or two too early
 // This should be gracefully handled by the dumping the interest that is due onto the loan.
 // That way, a premature interest payment would end up giving a little less daily interest for a
 // couple of days, and then when the rollover happens, there is nothing still "due".
+
+func TestLoanAccessors(t *testing.T) {
+	loan := NewLoan(mustBigRatFromString("1000"))
+	loan.interest.SetInt64(10)
+	loan.fees.SetInt64(5)
+	loan.surplus.SetInt64(1)
+
+	if want, got := "1014", loan.Owed().RatString(); want != got {
+		t.Errorf("want owed %s, but got %s", want, got)
+	}
+
+	// Accessors return copies.
+	loan.Balance().SetInt64(0)
+	if want, got := "1000", loan.Balance().RatString(); want != got {
+		t.Errorf("want balance %s, but got %s", want, got)
+	}
+
+	var zero Loan
+	if got := zero.Owed(); got.Sign() != 0 {
+		t.Errorf("want nothing owed on zero loan, but got %s", got)
+	}
+}
@@ -0,0 +1,123 @@
+package calc
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// OverpaymentPolicy determines what happens to payments in excess of
+// what is owed on a loan.
+type OverpaymentPolicy int
+
+const (
+	// OverpaymentSurplus stops the loan at zero and keeps the excess
+	// as a surplus that accrues no interest. The surplus settles
+	// anything owed later on, e.g. after a disbursement.
+	OverpaymentSurplus OverpaymentPolicy = iota
+	// OverpaymentCredit is like OverpaymentSurplus, but the surplus
+	// is a credit that accrues interest at the deposit rate.
+	OverpaymentCredit
+	// OverpaymentError makes an overpayment an error.
+	OverpaymentError
+)
+
+var overpaymentPolicyNames = []string{"surplus", "credit", "error"}
+
+func (p OverpaymentPolicy) String() string {
+	if p >= 0 && int(p) < len(overpaymentPolicyNames) {
+		return overpaymentPolicyNames[p]
+	}
+	return fmt.Sprintf("OverpaymentPolicy(%d)", int(p))
+}
+
+// ParseOverpaymentPolicy returns the policy named s, as returned by
+// [OverpaymentPolicy.String].
+func ParseOverpaymentPolicy(s string) (OverpaymentPolicy, error) {
+	for p, name := range overpaymentPolicyNames {
+		if strings.EqualFold(s, name) {
+			return OverpaymentPolicy(p), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown overpayment policy %q", s)
+}
+
+// OverpaidError is an overpayment under the [OverpaymentError] policy.
+type OverpaidError struct {
+	Day    time.Time
+	Amount *big.Rat
+}
+
+func (e *OverpaidError) Error() string {
+	return fmt.Sprintf("overpayment of %s on %s", e.Amount.FloatString(2), e.Day.Format(internal.DateLayout))
+}
+
+// SetOverpaymentPolicy sets the policy for payments in excess of what
+// is owed, and the deposit rates that a credit accrues under the
+// [OverpaymentCredit] policy. Days not covered by any deposit rate
+// have a zero deposit rate.
+func (b *Bank) SetOverpaymentPolicy(policy OverpaymentPolicy, depositRates []io.AnnualInterestRate) {
+	b.overpayment = policy
+	b.depositRates = sortedRates(depositRates)
+}
+
+// settleOverpayment settles what is owed on loan with its surplus, and
+// turns a negative balance into a surplus according to the overpayment
+// policy, once the accrued interest has been settled.
+func (b *Bank) settleOverpayment(day time.Time, loan *Loan) error {
+	if loan.surplus.Sign() > 0 {
+		for _, owed := range []*big.Rat{loan.fees, loan.penalty, loan.interest, loan.balance} {
+			if owed.Sign() <= 0 {
+				continue
+			}
+			settled := new(big.Rat).Set(owed)
+			if settled.Cmp(loan.surplus) > 0 {
+				settled.Set(loan.surplus)
+			}
+			owed.Sub(owed, settled)
+			loan.surplus.Sub(loan.surplus, settled)
+		}
+	}
+
+	if loan.balance.Sign() >= 0 {
+		return nil
+	}
+
+	excess := new(big.Rat).Neg(loan.balance)
+	loan.balance.SetInt64(0)
+
+	settled := new(big.Rat).Set(loan.interest)
+	if settled.Cmp(excess) > 0 {
+		settled.Set(excess)
+	}
+	loan.interest.Sub(loan.interest, settled)
+	excess.Sub(excess, settled)
+
+	if excess.Sign() == 0 {
+		return nil
+	}
+
+	if b.overpayment == OverpaymentError {
+		return &OverpaidError{Day: DateFromTime(day), Amount: excess}
+	}
+
+	loan.surplus.Add(loan.surplus, excess)
+	return nil
+}
+
+// creditInterest returns the interest accrued on surplus during day
+// under the [OverpaymentCredit] policy.
+func (b *Bank) creditInterest(day time.Time, surplus *big.Rat) *big.Rat {
+	if b.overpayment != OverpaymentCredit || surplus.Sign() <= 0 {
+		return new(big.Rat)
+	}
+
+	rate, _ := rateOn(b.depositRates, day)
+
+	y, m, _ := day.Date()
+	return new(big.Rat).Mul(annualToDaily(rate, daysInMonth(m, y)), surplus)
+}
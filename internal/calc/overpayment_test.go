@@ -0,0 +1,177 @@
+package calc
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+func overpaidBank(policy OverpaymentPolicy) Bank {
+	disbursement := io.MustNewTransaction(2022, 2, 1, "200")
+	disbursement.Kind = io.Disbursement
+
+	bank := NewBank([]io.Transaction{
+		io.MustNewTransaction(2022, 1, 10, "1500"),
+		disbursement,
+	}, []io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 1, 1, "0"),
+	})
+	bank.SetOverpaymentPolicy(policy, []io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 1, 1, "0.12"),
+	})
+
+	return bank
+}
+
+func TestOverpaymentSurplus(t *testing.T) {
+	bank := overpaidBank(OverpaymentSurplus)
+
+	days := mustSeries(t, &bank, NewLoan(mustBigRatFromString("1000")),
+		time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2022, 2, 28, 0, 0, 0, 0, time.UTC))
+
+	var paidOff []time.Time
+	for _, d := range days {
+		if d.PaidOff {
+			paidOff = append(paidOff, d.Date)
+		}
+		if d.Loan.balance.Sign() < 0 {
+			t.Fatalf("%s: want balance stopped at zero, but got %s", d.Date, d.Loan.balance)
+		}
+	}
+
+	if want := []time.Time{time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC)}; len(paidOff) != 1 || paidOff[0] != want[0] {
+		t.Errorf("want paid off on %v, but got %v", want, paidOff)
+	}
+
+	jan, _ := dayOf(days, time.Date(2022, 1, 31, 0, 0, 0, 0, time.UTC))
+	if want, got := mustBigRatFromString("500"), jan.Loan.surplus; want.Cmp(got) != 0 {
+		t.Errorf("want surplus %s, but got %s", want, got)
+	}
+
+	// The disbursement is settled by the surplus.
+	feb := days[len(days)-1]
+	if want, got := mustBigRatFromString("300"), feb.Loan.surplus; want.Cmp(got) != 0 {
+		t.Errorf("want surplus %s, but got %s", want, got)
+	}
+	if got := feb.Loan.balance; got.Sign() != 0 {
+		t.Errorf("want zero balance, but got %s", got)
+	}
+}
+
+func TestOverpaymentCredit(t *testing.T) {
+	bank := overpaidBank(OverpaymentCredit)
+
+	days := mustSeries(t, &bank, NewLoan(mustBigRatFromString("1000")),
+		time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2022, 2, 1, 0, 0, 0, 0, time.UTC))
+
+	// 500 at 12 % for the 22 days of January 10–31, capitalized on
+	// February 1, and settling the disbursement that day.
+	want := new(big.Rat).Add(mustBigRatFromString("300"), big.NewRat(22*500*12, 100*12*31))
+	if got := days[len(days)-1].Loan.surplus; want.Cmp(got) != 0 {
+		t.Errorf("want surplus %s, but got %s", want.FloatString(4), got.FloatString(4))
+	}
+
+	if got := days[len(days)-1].Interest; got.Sign() != 0 {
+		t.Errorf("want credit interest kept out of the interest, but got %s", got)
+	}
+}
+
+func TestOverpaymentError(t *testing.T) {
+	bank := overpaidBank(OverpaymentError)
+
+	days, err := Series(&bank, NewLoan(mustBigRatFromString("1000")),
+		time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		time.Date(2022, 1, 31, 0, 0, 0, 0, time.UTC))
+
+	var overpaid *OverpaidError
+	if !errors.As(err, &overpaid) {
+		t.Fatalf("want overpaid error, but got %v", err)
+	}
+	if want, got := mustBigRatFromString("500"), overpaid.Amount; want.Cmp(got) != 0 {
+		t.Errorf("want overpayment %s, but got %s", want, got)
+	}
+	if want, got := time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC), overpaid.Day; !want.Equal(got) {
+		t.Errorf("want overpayment on %s, but got %s", want, got)
+	}
+
+	// The days before the overpayment are processed.
+	if want, got := 9, len(days); want != got {
+		t.Errorf("want %d days, but got %d", want, got)
+	}
+}
+
+// TestRun_OverpaymentError checks that an overpayment is returned as an
+// error through Run, and not raised as a panic, since it is caused by
+// the input.
+func TestRun_OverpaymentError(t *testing.T) {
+	bank := overpaidBank(OverpaymentError)
+
+	var out bytes.Buffer
+	err := Run(context.Background(), &out, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), mustBigRatFromString("1000"), bank, ';')
+
+	var overpaid *OverpaidError
+	if !errors.As(err, &overpaid) {
+		t.Fatalf("want overpaid error, but got %v", err)
+	}
+	if want, got := time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC), overpaid.Day; !want.Equal(got) {
+		t.Errorf("want overpayment on %s, but got %s", want, got)
+	}
+}
+
+func TestRun_OverpaymentSurplus(t *testing.T) {
+	bank := NewBank([]io.Transaction{
+		io.MustNewTransaction(2022, 1, 10, "1500"),
+	}, []io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 1, 1, "0"),
+	})
+
+	var out bytes.Buffer
+	if err := Run(context.Background(), &out, time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), mustBigRatFromString("1000"), bank, ';'); err != nil {
+		t.Fatalf("running calculations: %s", err)
+	}
+
+	r := csv.NewReader(&out)
+	r.Comma = ';'
+	records, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV output: %s", err)
+	}
+
+	if want, got := []string{"Surplus", "Paid off"}, records[0][len(records[0])-2:]; want[0] != got[0] || want[1] != got[1] {
+		t.Fatalf("want last columns %v, but got %v", want, got)
+	}
+
+	for _, record := range records[1:] {
+		if record[0] != "2022-01-10" {
+			continue
+		}
+		if want, got := "500.00", record[len(record)-2]; want != got {
+			t.Errorf("want surplus %s, but got %s", want, got)
+		}
+		if want, got := "yes", record[len(record)-1]; want != got {
+			t.Errorf("want paid off %q, but got %q", want, got)
+		}
+		return
+	}
+	t.Error("want a record of 2022-01-10")
+}
+
+func TestParseOverpaymentPolicy(t *testing.T) {
+	for _, p := range []OverpaymentPolicy{OverpaymentSurplus, OverpaymentCredit, OverpaymentError} {
+		if got, err := ParseOverpaymentPolicy(p.String()); err != nil || got != p {
+			t.Errorf("want %s, but got %s (err=%v)", p, got, err)
+		}
+	}
+
+	if _, err := ParseOverpaymentPolicy("refund"); err == nil {
+		t.Error("want error for unknown policy")
+	}
+}
@@ -0,0 +1,132 @@
+package calc
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+	intio "gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// Part is one part (lånedel) of a loan split into several parts, each
+// with its own interest rates.
+type Part struct {
+	Name      string
+	Principal *big.Rat
+	// Share is the share, as a decimal, of each transaction allocated
+	// to the part. The shares of all parts of a loan must add up to
+	// one.
+	Share            *big.Rat
+	InterestRates    []intio.AnnualInterestRate
+	FixedRatePeriods []intio.FixedRatePeriod
+}
+
+// RunParts runs the calculations for a loan split into parts, given
+// the first day of the loan and the transactions made, which are
+// split between the parts according to their shares. Results are
+// written to w as CSV records—one record per day—indicating the state
+// of each part and the totals of all parts on each day.
+func RunParts(w io.Writer, firstDay time.Time, parts []Part, transactions []intio.Transaction, outComma rune) error {
+	shares := make([]share, len(parts))
+	for i, p := range parts {
+		shares[i] = share{name: p.Name, share: p.Share}
+	}
+	if err := checkShares("loan part", shares); err != nil {
+		return err
+	}
+
+	series := make([][]Day, len(parts))
+	for i, p := range parts {
+		bank := NewBank(splitTransactions(transactions, p.Share), p.InterestRates)
+		bank.AddFixedRatePeriods(p.FixedRatePeriods...)
+		days, err := Series(&bank, NewLoan(p.Principal), firstDay, time.Now())
+		if err != nil {
+			return fmt.Errorf("loan part %q: %w", p.Name, err)
+		}
+		series[i] = days
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = outComma
+
+	header := []string{"Date"}
+	for _, p := range parts {
+		header = append(header,
+			p.Name+" annual interest rate (%)",
+			p.Name+" balance",
+			p.Name+" accrued interest")
+	}
+	header = append(header, "Total balance", "Total accrued interest")
+	writer.Write(header)
+
+	for i := range series[0] {
+		record := []string{series[0][i].Date.Format(internal.DateLayout)}
+		balance, interest := new(big.Rat), new(big.Rat)
+
+		for _, s := range series {
+			day := s[i]
+
+			airText := "-"
+			if day.Rate != nil {
+				airText = new(big.Rat).Mul(day.Rate, big.NewRat(100, 1)).FloatString(2)
+			}
+
+			record = append(record,
+				airText,
+				day.Loan.balance.FloatString(2),
+				day.Loan.interest.FloatString(2))
+
+			balance.Add(balance, day.Loan.balance)
+			interest.Add(interest, day.Loan.interest)
+		}
+
+		record = append(record, balance.FloatString(2), interest.FloatString(2))
+		writer.Write(record)
+	}
+
+	return flush(writer)
+}
+
+// share is the named share of something, as a decimal.
+type share struct {
+	name  string
+	share *big.Rat
+}
+
+// checkShares checks that shares are non-negative and add up to one.
+// what names what the shares belong to in errors.
+func checkShares(what string, shares []share) error {
+	if len(shares) == 0 {
+		return fmt.Errorf("no %ss", what)
+	}
+
+	sum := new(big.Rat)
+	for _, s := range shares {
+		if s.share.Sign() < 0 {
+			return fmt.Errorf("%s %q has negative share %s", what, s.name, s.share.FloatString(2))
+		}
+		sum.Add(sum, s.share)
+	}
+
+	if sum.Cmp(big.NewRat(1, 1)) != 0 {
+		return fmt.Errorf("shares of %ss add up to %s, want 1", what, sum.FloatString(4))
+	}
+
+	return nil
+}
+
+// splitTransactions returns copies of transactions with their amounts
+// multiplied by share.
+func splitTransactions(transactions []intio.Transaction, share *big.Rat) []intio.Transaction {
+	split := make([]intio.Transaction, len(transactions))
+
+	for i, t := range transactions {
+		t.Amount = new(big.Rat).Mul(t.Amount, share)
+		split[i] = t
+	}
+
+	return split
+}
@@ -0,0 +1,76 @@
+package calc
+
+import (
+	"bytes"
+	"encoding/csv"
+	"path"
+	"testing"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+func TestRunParts(t *testing.T) {
+	transactions, err := io.ReadTransactions(path.Join("..", "testdata", "transactions.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading transactions: %s", err)
+	}
+
+	rates, err := io.ReadInterestRates(path.Join("..", "testdata", "annual_interest_rates.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading interest rates: %s", err)
+	}
+
+	parts := []Part{
+		{Name: "A", Principal: mustBigRatFromString("75000"), Share: mustBigRatFromString("0.75"), InterestRates: rates},
+		{Name: "B", Principal: mustBigRatFromString("25000"), Share: mustBigRatFromString("0.25"), InterestRates: rates},
+	}
+
+	firstDay := time.Date(2022, time.June, 7, 0, 0, 0, 0, time.UTC)
+
+	var outCSV bytes.Buffer
+	if err := RunParts(&outCSV, firstDay, parts, transactions, ','); err != nil {
+		t.Fatalf("running parts: %s", err)
+	}
+
+	records, err := csv.NewReader(&outCSV).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV output: %s", err)
+	}
+
+	if want, got := 9, len(records[0]); want != got {
+		t.Fatalf("want %d columns, but got %d: %v", want, got, records[0])
+	}
+
+	// The parts have the same rates and split the transactions in
+	// proportion to their principals, so they add up to the same
+	// balances as an undivided loan.
+	wantDateBalances := map[string][3]string{
+		"2022-08-10": {"72901.61", "24300.54", "97202.14"},
+		"2022-11-22": {"69130.23", "23043.41", "92173.64"},
+		"2023-01-01": {"69436.19", "23145.40", "92581.59"},
+	}
+
+	for _, record := range records[1:] {
+		want, ok := wantDateBalances[record[0]]
+		if !ok {
+			continue
+		}
+
+		if got := [3]string{record[2], record[5], record[7]}; want != got {
+			t.Errorf("%s: want balances %v, but got %v", record[0], want, got)
+		}
+	}
+}
+
+func TestRunParts_InvalidShares(t *testing.T) {
+	parts := []Part{
+		{Name: "A", Principal: mustBigRatFromString("50000"), Share: mustBigRatFromString("0.5")},
+		{Name: "B", Principal: mustBigRatFromString("50000"), Share: mustBigRatFromString("0.4")},
+	}
+
+	var out bytes.Buffer
+	if err := RunParts(&out, time.Now(), parts, nil, ','); err == nil {
+		t.Error("want error, but got nil")
+	}
+}
@@ -0,0 +1,80 @@
+package calc
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// ErrNotFixed is returned by [Bank.PrepaymentPenalty] when the day of
+// the prepayment is not within a fixed-rate period.
+var ErrNotFixed = errors.New("no fixed-rate period")
+
+// daysInOneYear is the day count used to express the remaining time of
+// a fixed-rate period in years.
+var daysInOneYear = big.NewRat(365, 1)
+
+// Penalty is the prepayment penalty (ränteskillnadsersättning) for an
+// extra amortization during a fixed-rate period.
+type Penalty struct {
+	Day    time.Time
+	Amount *big.Rat
+	Period io.FixedRatePeriod
+	// ComparisonRate is the comparison rate (jämförelseränta) on Day,
+	// including any addition.
+	ComparisonRate *big.Rat
+	// RemainingDays is the number of days from Day until the end of
+	// Period.
+	RemainingDays int
+	Penalty       *big.Rat
+}
+
+// PrepaymentPenalty calculates the penalty for amortizing amount on
+// day, within a fixed-rate period, following the standard Swedish
+// formula:
+//
+//	penalty = amount × (fixed rate − comparison rate) × remaining years
+//
+// where the comparison rate is the rate in comparisonRates in effect
+// on day plus addition (e.g. the difference between the mortgage bond
+// and government bond rates when the loan was taken), and the
+// remaining time runs until the end of the period, counted as days
+// over 365. No penalty is due if the comparison rate is at least the
+// fixed rate.
+func (b *Bank) PrepaymentPenalty(day time.Time, amount *big.Rat, comparisonRates []io.AnnualInterestRate, addition *big.Rat) (Penalty, error) {
+	day = DateFromTime(day)
+
+	period, ok := b.fixedRatePeriod(day)
+	if !ok {
+		return Penalty{}, fmt.Errorf("%s: %w", day.Format(internal.DateLayout), ErrNotFixed)
+	}
+
+	comparison, ok := rateOn(sortedRates(comparisonRates), day)
+	if !ok {
+		return Penalty{}, fmt.Errorf("no comparison rate on %s", day.Format(internal.DateLayout))
+	}
+	comparison.Add(comparison, addition)
+
+	remainingDays := int(period.End.Sub(day).Hours() / 24)
+
+	penalty := new(big.Rat).Sub(period.DecimalRate, comparison)
+	if penalty.Sign() < 0 {
+		penalty.SetInt64(0)
+	}
+	penalty.Mul(penalty, amount)
+	penalty.Mul(penalty, big.NewRat(int64(remainingDays), 1))
+	penalty.Quo(penalty, daysInOneYear)
+
+	return Penalty{
+		Day:            day,
+		Amount:         new(big.Rat).Set(amount),
+		Period:         period,
+		ComparisonRate: comparison,
+		RemainingDays:  remainingDays,
+		Penalty:        penalty,
+	}, nil
+}
@@ -0,0 +1,81 @@
+package calc
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+func TestPrepaymentPenalty(t *testing.T) {
+	bank := NewBank(nil, []io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 1, 1, "0.0114"),
+	})
+	bank.AddFixedRatePeriods(io.FixedRatePeriod{
+		Start:       time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC),
+		End:         time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+		DecimalRate: mustBigRatFromString("0.032"),
+	})
+
+	comparisonRates := []io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 1, 1, "0.035"),
+		io.MustNewAnnualInterestRate(2023, 6, 1, "0.02"),
+	}
+
+	tests := []struct {
+		name        string
+		day         time.Time
+		addition    string
+		wantDays    int
+		wantPenalty string
+	}{
+		{
+			name:        "comparison rate below fixed rate",
+			day:         time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC),
+			addition:    "0",
+			wantDays:    366,
+			wantPenalty: "120.33",
+		},
+		{
+			name:        "with addition",
+			day:         time.Date(2023, 10, 1, 0, 0, 0, 0, time.UTC),
+			addition:    "0.005",
+			wantDays:    366,
+			wantPenalty: "70.19",
+		},
+		{
+			name:        "comparison rate above fixed rate",
+			day:         time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+			addition:    "0",
+			wantDays:    639,
+			wantPenalty: "0.00",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := bank.PrepaymentPenalty(tt.day, mustBigRatFromString("10000"),
+				comparisonRates, mustBigRatFromString(tt.addition))
+			if err != nil {
+				t.Fatalf("calculating penalty: %s", err)
+			}
+
+			if want, got := tt.wantDays, p.RemainingDays; want != got {
+				t.Errorf("want %d remaining days, but got %d", want, got)
+			}
+
+			if want, got := tt.wantPenalty, p.Penalty.FloatString(2); want != got {
+				t.Errorf("want penalty %s, but got %s", want, got)
+			}
+		})
+	}
+
+	t.Run("not fixed", func(t *testing.T) {
+		_, err := bank.PrepaymentPenalty(time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+			mustBigRatFromString("10000"), comparisonRates, mustBigRatFromString("0"))
+		if !errors.Is(err, ErrNotFixed) {
+			t.Errorf("want %v, but got %v", ErrNotFixed, err)
+		}
+	})
+}
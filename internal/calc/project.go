@@ -0,0 +1,96 @@
+package calc
+
+import (
+	"math/big"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// plannedPaymentType is the type of the transactions made by a payment
+// plan in a projection.
+const plannedPaymentType = "Planerad betalning"
+
+// Project processes loan with bank one day at a time, from the first
+// through the last day (both inclusive), like [Series], while making
+// the payments of plan on their due days. A planned payment never
+// exceeds the amount owed, and the projection ends early on the day
+// the loan is paid off.
+//
+// The returned bank holds the planned payments in addition to the
+// transactions of bank. Like with [Series], the projection stops with
+// an error at the first day that cannot be processed.
+func Project(bank Bank, loan Loan, first, last time.Time, plan []io.PlannedPayment) ([]Day, Bank, error) {
+	plan = append([]io.PlannedPayment(nil), plan...)
+	io.SortPlan(plan)
+
+	start := DateFromTime(first)
+	end := DateFromTime(last).AddDate(0, 0, 1)
+
+	// The planned payments are added to a copy of those of bank, which
+	// is left unchanged.
+	planned := make(map[time.Time][]io.Transaction, len(bank.planned))
+	for d, ts := range bank.planned {
+		planned[d] = ts[:len(ts):len(ts)]
+	}
+	bank.planned = planned
+
+	var days []Day
+
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		payment, payoff := plannedPayment(&bank, day, loan, plan)
+		if payment != nil {
+			bank.planned[day] = append(bank.planned[day], io.Transaction{
+				Date:        day,
+				Type:        plannedPaymentType,
+				Description: plannedPaymentType,
+				Amount:      payment,
+				Kind:        io.Payment,
+			})
+		}
+
+		d, err := step(&bank, day, loan)
+		if err != nil {
+			return days, bank, err
+		}
+
+		if payoff {
+			d.Loan = NewLoan(new(big.Rat))
+			d.Interest = new(big.Rat)
+		}
+
+		loan = d.Loan
+		days = append(days, d)
+
+		if payoff {
+			break
+		}
+	}
+
+	return days, bank, nil
+}
+
+// plannedPayment returns the payment due on day according to plan,
+// or nil if none is due. It is limited to the amount owed after the
+// other transactions and fees of the day, and payoff is true if it
+// pays off the loan.
+func plannedPayment(bank *Bank, day time.Time, loan Loan, plan []io.PlannedPayment) (payment *big.Rat, payoff bool) {
+	entry, ok := io.PlanOn(plan, day)
+	if !ok || !entry.DueOn(day) {
+		return nil, false
+	}
+
+	owed := loan.owed()
+	owed.Sub(owed, bank.transactionsAmount(day))
+	owed.Add(owed, bank.feesAmount(day))
+
+	if owed.Sign() <= 0 {
+		return nil, false
+	}
+
+	if entry.Amount.Cmp(owed) >= 0 {
+		return owed, true
+	}
+
+	return new(big.Rat).Set(entry.Amount), false
+}
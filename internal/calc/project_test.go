@@ -0,0 +1,58 @@
+package calc
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+func TestProject(t *testing.T) {
+	bank := NewBank(nil, []io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 1, 1, "0.03"),
+	})
+	loan := NewLoan(mustBigRatFromString("10000"))
+	first := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(2030, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	plan := []io.PlannedPayment{
+		{From: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Day: 27, Amount: mustBigRatFromString("1000")},
+		{From: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Day: 27, Amount: mustBigRatFromString("500")},
+	}
+
+	days, b := mustProject(t, bank, loan, first, last, plan)
+
+	// 12 payments of 500 in 2023 leave a bit more than 4000 plus
+	// interest, paid off by the fifth payment of 1000 in 2024.
+	payoff := days[len(days)-1]
+
+	if want, got := time.Date(2024, 5, 27, 0, 0, 0, 0, time.UTC), payoff.Date; want != got {
+		t.Errorf("want payoff on %s, but got %s", want, got)
+	}
+
+	if payoff.Loan.owed().Sign() != 0 {
+		t.Errorf("want nothing owed on payoff, but got %s", payoff.Loan.owed())
+	}
+
+	planned := 0
+	for _, ts := range b.planned {
+		planned += len(ts)
+	}
+	if want, got := 17, planned; want != got {
+		t.Errorf("want %d planned payments, but got %d", want, got)
+	}
+	if len(bank.planned) != 0 {
+		t.Errorf("want the planned payments left out of the given bank")
+	}
+
+	// The payments add up to the principal plus the interest.
+	s := Summarize(&b, days)
+	if want, got := s.Payments, new(big.Rat).Add(mustBigRatFromString("10000"), s.Interest); want.Cmp(got) != 0 {
+		t.Errorf("want payments %s to equal principal plus interest %s", want.FloatString(2), got.FloatString(2))
+	}
+
+	if want, got := "500.00", b.paymentsAmount(time.Date(2023, 6, 27, 0, 0, 0, 0, time.UTC)).FloatString(2); want != got {
+		t.Errorf("want planned payment %s, but got %s", want, got)
+	}
+}
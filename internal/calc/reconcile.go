@@ -0,0 +1,250 @@
+package calc
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// Cause is the likely cause of a deviation between a calculated and
+// a reported loan state.
+type Cause int
+
+const (
+	// CauseMissingTransaction means that the transactions used in
+	// the calculation likely lack (or have an extra) transaction.
+	CauseMissingTransaction Cause = iota
+	// CauseRateChangeOffByOne means that an interest rate change
+	// likely took effect one day earlier or later than stated.
+	CauseRateChangeOffByOne
+	// CauseRounding means that the deviation is small enough to be
+	// explained by the bank rounding amounts differently.
+	CauseRounding
+)
+
+func (c Cause) String() string {
+	switch c {
+	case CauseMissingTransaction:
+		return "missing transaction"
+	case CauseRateChangeOffByOne:
+		return "rate change date off by one"
+	case CauseRounding:
+		return "rounding"
+	default:
+		return fmt.Sprintf("Cause(%d)", int(c))
+	}
+}
+
+// roundingPerMonth is how much a calculated amount is allowed to
+// deviate per capitalized month to still be explained by rounding.
+var roundingPerMonth = big.NewRat(1, 100)
+
+// Deviation describes a checkpoint whose reported loan state deviates
+// from the calculated one by more than the tolerance.
+type Deviation struct {
+	Checkpoint io.Checkpoint
+	// Balance and Interest are the calculated values on the day of
+	// the checkpoint.
+	Balance  *big.Rat
+	Interest *big.Rat
+	// BalanceDiff and InterestDiff are the calculated values minus
+	// the reported ones. InterestDiff is nil if the checkpoint has no
+	// reported interest.
+	BalanceDiff  *big.Rat
+	InterestDiff *big.Rat
+	Cause        Cause
+	// Detail is a human readable explanation of Cause.
+	Detail string
+}
+
+// Reconcile calculates loan with bank from the first day through the
+// last checkpoint and compares the result with each checkpoint. A
+// deviation is returned, in checkpoint order, for every checkpoint
+// where the balance or interest differs by more than tolerance.
+//
+// The likely cause of each deviation is found by first recalculating
+// with each interest rate change moved one day back or forth; if any
+// such change makes the checkpoint match, the rate change date is
+// blamed. Otherwise, a deviation of at most one cent per capitalized
+// month (on top of the tolerance) is blamed on rounding, and anything
+// larger on a missing transaction.
+//
+// An error is returned if a checkpoint is before the first day, or if
+// the loan cannot be processed (see [Bank.Process]).
+func Reconcile(bank Bank, loan Loan, first time.Time, checkpoints []io.Checkpoint, tolerance *big.Rat) ([]Deviation, error) {
+	if len(checkpoints) == 0 {
+		return nil, nil
+	}
+
+	first = DateFromTime(first)
+	last := first
+	for _, c := range checkpoints {
+		if DateFromTime(c.Day).Before(first) {
+			return nil, fmt.Errorf("checkpoint on %s is before the first day %s",
+				c.Day.Format(internal.DateLayout), first.Format(internal.DateLayout))
+		}
+		if c.Day.After(last) {
+			last = DateFromTime(c.Day)
+		}
+	}
+
+	at := checkpointDays(checkpoints)
+
+	days, err := SeriesAt(&bank, loan, first, last, at)
+	if err != nil {
+		return nil, err
+	}
+	var shifted []shiftedSeries // calculated lazily
+
+	var deviations []Deviation
+
+	for _, c := range checkpoints {
+		day, ok := dayOf(days, c.Day)
+		if !ok {
+			continue
+		}
+
+		dev, ok := deviation(c, day.Loan, tolerance)
+		if !ok {
+			continue
+		}
+
+		if shifted == nil {
+			shifted = shiftRateChanges(bank, loan, first, last, at)
+		}
+
+		limit := new(big.Rat).Mul(roundingPerMonth, big.NewRat(int64(capitalizations(first, c.Day)), 1))
+		limit.Add(limit, tolerance)
+
+		dev.Cause, dev.Detail = CauseMissingTransaction, fmt.Sprintf(
+			"a transaction of about %s may be missing or wrong", dev.BalanceDiff.FloatString(2))
+
+		if s, ok := matchingShift(shifted, c, tolerance); ok {
+			dev.Cause, dev.Detail = CauseRateChangeOffByOne, s.String()
+		} else if withinTolerance(dev.BalanceDiff, limit) &&
+			(dev.InterestDiff == nil || withinTolerance(dev.InterestDiff, limit)) {
+			dev.Cause, dev.Detail = CauseRounding, fmt.Sprintf(
+				"within %s after %d capitalization(s)", limit.FloatString(2), capitalizations(first, c.Day))
+		}
+
+		deviations = append(deviations, dev)
+	}
+
+	return deviations, nil
+}
+
+// deviation compares a checkpoint with the calculated loan state. It
+// returns ok if they differ by more than tolerance.
+func deviation(c io.Checkpoint, loan Loan, tolerance *big.Rat) (dev Deviation, ok bool) {
+	dev = Deviation{
+		Checkpoint:  c,
+		Balance:     new(big.Rat).Set(loan.balance),
+		Interest:    new(big.Rat).Set(loan.interest),
+		BalanceDiff: new(big.Rat).Sub(loan.balance, c.Balance),
+	}
+
+	ok = !withinTolerance(dev.BalanceDiff, tolerance)
+
+	if c.Interest != nil {
+		dev.InterestDiff = new(big.Rat).Sub(loan.interest, c.Interest)
+		ok = ok || !withinTolerance(dev.InterestDiff, tolerance)
+	}
+
+	return dev, ok
+}
+
+func withinTolerance(diff, tolerance *big.Rat) bool {
+	return new(big.Rat).Abs(diff).Cmp(tolerance) <= 0
+}
+
+// shiftedSeries is a series calculated with the change to rate moved
+// the given number of days.
+type shiftedSeries struct {
+	rate   io.AnnualInterestRate
+	days   int
+	series []Day
+}
+
+func (s shiftedSeries) String() string {
+	return fmt.Sprintf("rate change on %s likely took effect on %s",
+		s.rate.Day.Format(internal.DateLayout),
+		s.rate.Day.AddDate(0, 0, s.days).Format(internal.DateLayout))
+}
+
+func shiftRateChanges(bank Bank, loan Loan, first, last time.Time, at []time.Time) []shiftedSeries {
+	shifted := []shiftedSeries{}
+
+	for i, r := range bank.interestRates {
+		if r.Day.After(last) {
+			break
+		}
+
+		for _, days := range []int{-1, 1} {
+			rates := make([]io.AnnualInterestRate, len(bank.interestRates))
+			copy(rates, bank.interestRates)
+			rates[i].Day = r.Day.AddDate(0, 0, days)
+
+			b := bank.withInterestRates(rates)
+			series, err := SeriesAt(&b, loan, first, last, at)
+			if err != nil {
+				// The shifted rates do not cover the loan.
+				continue
+			}
+
+			shifted = append(shifted, shiftedSeries{
+				rate:   r,
+				days:   days,
+				series: series,
+			})
+		}
+	}
+
+	return shifted
+}
+
+func matchingShift(shifted []shiftedSeries, c io.Checkpoint, tolerance *big.Rat) (shiftedSeries, bool) {
+	for _, s := range shifted {
+		if day, ok := dayOf(s.series, c.Day); ok {
+			if _, ok := deviation(c, day.Loan, tolerance); !ok {
+				return s, true
+			}
+		}
+	}
+	return shiftedSeries{}, false
+}
+
+// checkpointDays returns the days of checkpoints.
+func checkpointDays(checkpoints []io.Checkpoint) []time.Time {
+	days := make([]time.Time, len(checkpoints))
+	for i, c := range checkpoints {
+		days[i] = c.Day
+	}
+	return days
+}
+
+// dayOf returns the day in days, which must be sorted by date, with
+// the same date as t.
+func dayOf(days []Day, t time.Time) (Day, bool) {
+	t = DateFromTime(t)
+
+	i := sort.Search(len(days), func(i int) bool {
+		return !days[i].Date.Before(t)
+	})
+	if i == len(days) || !days[i].Date.Equal(t) {
+		return Day{}, false
+	}
+
+	return days[i], true
+}
+
+// capitalizations returns the number of times interest is capitalized
+// after the first day up to and including the last day.
+func capitalizations(first, last time.Time) int {
+	fy, fm, _ := DateFromTime(first).Date()
+	ly, lm, _ := DateFromTime(last).Date()
+	return (ly-fy)*12 + int(lm) - int(fm)
+}
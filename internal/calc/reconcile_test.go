@@ -0,0 +1,139 @@
+package calc
+
+import (
+	"math/big"
+	"path"
+	"testing"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+func TestReconcile(t *testing.T) {
+	transactions, err := io.ReadTransactions(path.Join("..", "testdata", "transactions.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading transactions: %s", err)
+	}
+
+	rates, err := io.ReadInterestRates(path.Join("..", "testdata", "annual_interest_rates.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading interest rates: %s", err)
+	}
+
+	checkpoints, err := io.ReadCheckpoints(path.Join("..", "testdata", "checkpoints.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading checkpoints: %s", err)
+	}
+
+	bank := NewBank(transactions, rates)
+	loan := NewLoan(mustBigRatFromString("100000"))
+	firstDay := time.Date(2022, time.June, 7, 0, 0, 0, 0, time.UTC)
+	tolerance := mustBigRatFromString("0.01")
+
+	t.Run("matching", func(t *testing.T) {
+		got, err := Reconcile(bank, loan, firstDay, checkpoints, tolerance)
+		if err != nil {
+			t.Fatalf("reconciling: %s", err)
+		}
+		if len(got) != 0 {
+			t.Errorf("want no deviations, but got %+v", got)
+		}
+	})
+
+	tests := []struct {
+		name       string
+		checkpoint io.Checkpoint
+		wantCause  Cause
+	}{
+		{
+			name: "rate change off by one",
+			checkpoint: io.Checkpoint{
+				Day:      time.Date(2022, 9, 21, 0, 0, 0, 0, time.UTC),
+				Balance:  mustBigRatFromString("97336.18"),
+				Interest: reportedInterest(t, bank, loan, firstDay, time.Date(2022, 9, 21, 0, 0, 0, 0, time.UTC)),
+			},
+			wantCause: CauseRateChangeOffByOne,
+		},
+		{
+			name: "rounding",
+			checkpoint: io.Checkpoint{
+				Day:     time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+				Balance: mustBigRatFromString("92581.56"),
+			},
+			wantCause: CauseRounding,
+		},
+		{
+			name: "missing transaction",
+			checkpoint: io.Checkpoint{
+				Day:     time.Date(2022, 11, 23, 0, 0, 0, 0, time.UTC),
+				Balance: mustBigRatFromString("91673.64"),
+			},
+			wantCause: CauseMissingTransaction,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Reconcile(bank, loan, firstDay, []io.Checkpoint{tt.checkpoint}, tolerance)
+			if err != nil {
+				t.Fatalf("reconciling: %s", err)
+			}
+
+			if len(got) != 1 {
+				t.Fatalf("want 1 deviation, but got %d", len(got))
+			}
+
+			t.Logf("%+v", got[0])
+
+			if want, got := tt.wantCause, got[0].Cause; want != got {
+				t.Errorf("want cause %s, but got %s", want, got)
+			}
+		})
+	}
+}
+
+func TestReconcile_BeforeFirstDay(t *testing.T) {
+	bank := NewBank(nil, []io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 1, 1, "0.03"),
+	})
+	checkpoints := []io.Checkpoint{
+		{Day: time.Date(2022, 1, 5, 0, 0, 0, 0, time.UTC), Balance: mustBigRatFromString("1000")},
+	}
+
+	_, err := Reconcile(bank, NewLoan(mustBigRatFromString("1000")), time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC), checkpoints, new(big.Rat))
+	if err == nil {
+		t.Error("want error, but got nil")
+	}
+}
+
+// reportedInterest returns the accrued interest on day calculated as
+// if the third interest rate change happened one day later.
+func reportedInterest(t *testing.T, bank Bank, loan Loan, first, day time.Time) *big.Rat {
+	t.Helper()
+
+	rates := make([]io.AnnualInterestRate, len(bank.interestRates))
+	copy(rates, bank.interestRates)
+	rates[2].Day = rates[2].Day.AddDate(0, 0, 1)
+
+	b := bank.withInterestRates(rates)
+	days := mustSeries(t, &b, loan, first, day)
+
+	return days[len(days)-1].Loan.interest
+}
+
+func TestCapitalizations(t *testing.T) {
+	tests := []struct {
+		first, last time.Time
+		want        int
+	}{
+		{time.Date(2022, 6, 7, 0, 0, 0, 0, time.UTC), time.Date(2022, 6, 30, 0, 0, 0, 0, time.UTC), 0},
+		{time.Date(2022, 6, 7, 0, 0, 0, 0, time.UTC), time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC), 1},
+		{time.Date(2022, 6, 7, 0, 0, 0, 0, time.UTC), time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), 7},
+	}
+
+	for _, tt := range tests {
+		if got := capitalizations(tt.first, tt.last); got != tt.want {
+			t.Errorf("capitalizations(%s, %s): want %d, but got %d", tt.first, tt.last, tt.want, got)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package calc
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// ReferencePricing describes a loan priced as a reference rate (e.g.
+// STIBOR 3M or the Riksbank policy rate) plus a margin, where the
+// interest rate is reset at a fixed frequency.
+type ReferencePricing struct {
+	// Reference is the history of the reference rate.
+	Reference []io.AnnualInterestRate
+	// Margins is the history of the margin added to the reference
+	// rate. A day not covered by any margin has a zero margin.
+	Margins []io.AnnualInterestRate
+	// ResetMonths is the number of months between rate resets, e.g.
+	// 3 for a quarterly reset.
+	ResetMonths int
+	// Floor floors the reference rate, and thereby the effective
+	// rate, at zero.
+	Floor bool
+}
+
+// Rates returns the effective annual interest rates of the loan, for
+// use with [NewBank], given the day of the first reset (typically the
+// first day of the loan).
+//
+// On each reset day, the effective rate is set to the reference rate
+// plus the margin in effect that day, and it stays the same until the
+// next reset. Changes of either the reference rate or the margin thus
+// take effect on the first reset on or after the change. Resets are
+// calculated until both inputs stay the same; the last rate applies
+// indefinitely. A reset that would fall after the end of its month,
+// e.g. one month after January 31, falls on the last day of the month.
+func (p ReferencePricing) Rates(firstReset time.Time) ([]io.AnnualInterestRate, error) {
+	if p.ResetMonths < 1 {
+		return nil, fmt.Errorf("reset frequency must be at least one month, got %d", p.ResetMonths)
+	}
+
+	reference := sortedRates(p.Reference)
+	margins := sortedRates(p.Margins)
+
+	firstReset = DateFromTime(firstReset)
+	lastChange := firstReset
+	for _, rates := range [][]io.AnnualInterestRate{reference, margins} {
+		if n := len(rates); n > 0 && rates[n-1].Day.After(lastChange) {
+			lastChange = rates[n-1].Day
+		}
+	}
+
+	effective := []io.AnnualInterestRate{}
+	zero := new(big.Rat)
+
+	for i := 0; ; i++ {
+		reset := addMonths(firstReset, i*p.ResetMonths)
+
+		rate, ok := rateOn(reference, reset)
+		if !ok {
+			return nil, fmt.Errorf("no reference rate on reset day %s", reset.Format(internal.DateLayout))
+		}
+		if p.Floor && rate.Cmp(zero) < 0 {
+			rate.Set(zero)
+		}
+
+		margin, _ := rateOn(margins, reset)
+		rate.Add(rate, margin)
+		if p.Floor && rate.Cmp(zero) < 0 {
+			rate.Set(zero)
+		}
+
+		if n := len(effective); n == 0 || effective[n-1].DecimalRate.Cmp(rate) != 0 {
+			effective = append(effective, io.AnnualInterestRate{Day: reset, DecimalRate: rate})
+		}
+
+		if reset.After(lastChange) {
+			break
+		}
+	}
+
+	return effective, nil
+}
+
+// addMonths returns the same day of the month as day, the given number
+// of months later, or the last day of that month if it is shorter.
+func addMonths(day time.Time, months int) time.Time {
+	first := time.Date(day.Year(), day.Month()+time.Month(months), 1, 0, 0, 0, 0, time.UTC)
+	d := day.Day()
+	if n := daysInMonth(first.Month(), first.Year()); d > n {
+		d = n
+	}
+	return first.AddDate(0, 0, d-1)
+}
+
+// sortedRates returns a copy of rates sorted by day.
+func sortedRates(rates []io.AnnualInterestRate) []io.AnnualInterestRate {
+	sorted := append([]io.AnnualInterestRate(nil), rates...)
+	sort.SliceStable(sorted, func(i int, j int) bool {
+		return sorted[i].Day.Before(sorted[j].Day)
+	})
+	return sorted
+}
@@ -0,0 +1,130 @@
+package calc
+
+import (
+	"testing"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+func TestReferencePricingRates(t *testing.T) {
+	reference := []io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 8, 20, "0.025"),
+		io.MustNewAnnualInterestRate(2022, 1, 1, "-0.0005"),
+		io.MustNewAnnualInterestRate(2022, 4, 15, "0.01"),
+	}
+	margins := []io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 1, 1, "0.015"),
+		io.MustNewAnnualInterestRate(2022, 9, 1, "0.012"),
+	}
+	firstReset := time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		pricing ReferencePricing
+		want    []io.AnnualInterestRate
+	}{
+		{
+			name:    "quarterly with floor",
+			pricing: ReferencePricing{Reference: reference, Margins: margins, ResetMonths: 3, Floor: true},
+			want: []io.AnnualInterestRate{
+				io.MustNewAnnualInterestRate(2022, 1, 10, "0.015"),
+				io.MustNewAnnualInterestRate(2022, 7, 10, "0.025"),
+				io.MustNewAnnualInterestRate(2022, 10, 10, "0.037"),
+			},
+		},
+		{
+			name:    "quarterly without floor",
+			pricing: ReferencePricing{Reference: reference, Margins: margins, ResetMonths: 3},
+			want: []io.AnnualInterestRate{
+				io.MustNewAnnualInterestRate(2022, 1, 10, "0.0145"),
+				io.MustNewAnnualInterestRate(2022, 7, 10, "0.025"),
+				io.MustNewAnnualInterestRate(2022, 10, 10, "0.037"),
+			},
+		},
+		{
+			name:    "monthly without margins",
+			pricing: ReferencePricing{Reference: reference, ResetMonths: 1, Floor: true},
+			want: []io.AnnualInterestRate{
+				io.MustNewAnnualInterestRate(2022, 1, 10, "0"),
+				io.MustNewAnnualInterestRate(2022, 5, 10, "0.01"),
+				io.MustNewAnnualInterestRate(2022, 9, 10, "0.025"),
+			},
+		},
+		{
+			name: "negative margin floored",
+			pricing: ReferencePricing{
+				Reference:   reference,
+				Margins:     []io.AnnualInterestRate{io.MustNewAnnualInterestRate(2022, 1, 1, "-0.02")},
+				ResetMonths: 6,
+				Floor:       true,
+			},
+			want: []io.AnnualInterestRate{
+				io.MustNewAnnualInterestRate(2022, 1, 10, "0"),
+				io.MustNewAnnualInterestRate(2023, 1, 10, "0.005"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.pricing.Rates(firstReset)
+			if err != nil {
+				t.Fatalf("calculating rates: %s", err)
+			}
+
+			if want, got := len(tt.want), len(got); want != got {
+				t.Fatalf("want %d rates, but got %d: %v", want, got, got)
+			}
+
+			for i, got := range got {
+				if !tt.want[i].Equal(got) {
+					t.Errorf("i=%d, want %s, but got %s", i, tt.want[i], got)
+				}
+			}
+		})
+	}
+}
+
+func TestReferencePricingRates_MonthEnd(t *testing.T) {
+	pricing := ReferencePricing{
+		Reference: []io.AnnualInterestRate{
+			io.MustNewAnnualInterestRate(2022, 1, 1, "0.01"),
+			io.MustNewAnnualInterestRate(2022, 2, 28, "0.02"),
+			io.MustNewAnnualInterestRate(2022, 4, 30, "0.03"),
+		},
+		ResetMonths: 1,
+	}
+
+	got, err := pricing.Rates(time.Date(2022, 1, 31, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("calculating rates: %s", err)
+	}
+
+	want := []io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 1, 31, "0.01"),
+		io.MustNewAnnualInterestRate(2022, 2, 28, "0.02"),
+		io.MustNewAnnualInterestRate(2022, 4, 30, "0.03"),
+	}
+
+	if want, got := len(want), len(got); want != got {
+		t.Fatalf("want %d rates, but got %d: %v", want, got, got)
+	}
+
+	for i, got := range got {
+		if !want[i].Equal(got) {
+			t.Errorf("i=%d, want %s, but got %s", i, want[i], got)
+		}
+	}
+}
+
+func TestReferencePricingRates_NoReference(t *testing.T) {
+	pricing := ReferencePricing{
+		Reference:   []io.AnnualInterestRate{io.MustNewAnnualInterestRate(2022, 2, 1, "0.01")},
+		ResetMonths: 3,
+	}
+
+	if _, err := pricing.Rates(time.Date(2022, 1, 10, 0, 0, 0, 0, time.UTC)); err == nil {
+		t.Error("want error, but got nil")
+	}
+}
@@ -0,0 +1,313 @@
+package calc
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"math/rand"
+	"sort"
+	"strconv"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+	intio "gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// ErrTooLittleHistory is returned by FitRateModel when the interest
+// rate history is too short to fit a model to.
+var ErrTooLittleHistory = errors.New("too little interest rate history")
+
+// RateModel is a mean-reverting (Vasicek) model of the interest rate,
+// which changes once a month by
+//
+//	Δr = Reversion × (Mean − r) + Volatility × ε
+//
+// where ε is standard normally distributed.
+type RateModel struct {
+	// Reversion is the share of the distance to Mean that the rate
+	// reverts each month, between 0 (a random walk) and 1.
+	Reversion float64
+	// Mean is the long-term mean of the rate in decimal form.
+	Mean float64
+	// Volatility is the standard deviation of the monthly random
+	// change of the rate in decimal form.
+	Volatility float64
+}
+
+func (m RateModel) String() string {
+	return fmt.Sprintf("reversion %.4f/month, mean %.2f %%, volatility %.2f pp/month",
+		m.Reversion, m.Mean*100, m.Volatility*100)
+}
+
+// FitRateModel fits a model to rates, sampled on the first of each
+// month from the first rate until the last day, by ordinary least
+// squares regression of the monthly change of the rate on the rate.
+func FitRateModel(rates []intio.AnnualInterestRate, last time.Time) (RateModel, error) {
+	sorted := sortedRates(rates)
+	if len(sorted) == 0 {
+		return RateModel{}, ErrTooLittleHistory
+	}
+
+	var samples []float64
+	first := sorted[0].Day
+	for month := time.Date(first.Year(), first.Month(), 1, 0, 0, 0, 0, time.UTC); !month.After(last); month = month.AddDate(0, 1, 0) {
+		if rate, ok := rateOn(sorted, month); ok {
+			f, _ := rate.Float64()
+			samples = append(samples, f)
+		}
+	}
+	if len(samples) < 3 {
+		return RateModel{}, ErrTooLittleHistory
+	}
+
+	n := float64(len(samples) - 1)
+	var meanX, meanY float64
+	for i := 1; i < len(samples); i++ {
+		meanX += samples[i-1] / n
+		meanY += (samples[i] - samples[i-1]) / n
+	}
+
+	var covXY, varX float64
+	for i := 1; i < len(samples); i++ {
+		x, y := samples[i-1]-meanX, samples[i]-samples[i-1]-meanY
+		covXY += x * y
+		varX += x * x
+	}
+
+	var m RateModel
+	var slope, intercept float64
+
+	if varX > 0 {
+		slope = covXY / varX
+	}
+	if slope < 0 {
+		m.Reversion = math.Min(-slope, 1)
+		intercept = meanY - slope*meanX
+		m.Mean = intercept / -slope
+	} else {
+		// The rate does not revert; treat it as a random walk
+		// around the mean of the history.
+		slope, intercept = 0, meanY
+		m.Mean = meanX
+	}
+
+	var ss float64
+	for i := 1; i < len(samples); i++ {
+		residual := samples[i] - samples[i-1] - (intercept + slope*samples[i-1])
+		ss += residual * residual
+	}
+	m.Volatility = math.Sqrt(ss / n)
+
+	return m, nil
+}
+
+// Path returns a random path of the interest rate starting at rate on
+// the first day, with a change on the first of each following month
+// until the last day. Rates are rounded to whole basis points, which
+// keeps the calculations with them exact and fast.
+func (m RateModel) Path(rng *rand.Rand, rate *big.Rat, first, last time.Time) []intio.AnnualInterestRate {
+	first = DateFromTime(first)
+	path := []intio.AnnualInterestRate{{Day: first, DecimalRate: new(big.Rat).Set(rate)}}
+
+	r, _ := rate.Float64()
+	month := time.Date(first.Year(), first.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for month = month.AddDate(0, 1, 0); !month.After(last); month = month.AddDate(0, 1, 0) {
+		r += m.Reversion*(m.Mean-r) + m.Volatility*rng.NormFloat64()
+		bps := math.Round(r * 10000)
+		r = bps / 10000
+
+		path = append(path, intio.AnnualInterestRate{Day: month, DecimalRate: big.NewRat(int64(bps), 10000)})
+	}
+
+	return path
+}
+
+// Simulation holds the outcomes of projecting a loan with random
+// interest rate paths, sampled at the end of each month.
+type Simulation struct {
+	Dates []time.Time
+	// Balances and Interest hold, for each date, the balance and the
+	// cumulative interest of each path, sorted in ascending order.
+	Balances [][]*big.Rat
+	Interest [][]*big.Rat
+	// PaidOff holds, for each date, the number of paths in which the
+	// loan is paid off.
+	PaidOff []int
+}
+
+// Simulate projects loan with bank and plan from the first through the
+// last day (see [Project]) once for each of the given number of random
+// interest rate paths of model. The floating rate starts at the rate in
+// effect on the first day; fixed-rate periods are not affected. The
+// paths are generated from seed, so the same seed gives the same
+// simulation.
+func Simulate(bank Bank, loan Loan, first, last time.Time, plan []intio.PlannedPayment, model RateModel, paths int, seed int64) (Simulation, error) {
+	first, last = DateFromTime(first), DateFromTime(last)
+
+	rate, ok := rateOn(bank.interestRates, first)
+	if !ok {
+		return Simulation{}, fmt.Errorf("no interest rate on %s", first.Format(internal.DateLayout))
+	}
+
+	var sim Simulation
+	for d := first; !d.After(last); d = d.AddDate(0, 0, 1) {
+		if next := d.AddDate(0, 0, 1); next.Day() == 1 || d.Equal(last) {
+			sim.Dates = append(sim.Dates, d)
+		}
+	}
+
+	sim.Balances = make([][]*big.Rat, len(sim.Dates))
+	sim.Interest = make([][]*big.Rat, len(sim.Dates))
+	sim.PaidOff = make([]int, len(sim.Dates))
+
+	rng := rand.New(rand.NewSource(seed))
+
+	for p := 0; p < paths; p++ {
+		path := model.Path(rng, rate, first, last)
+		rates := append([]intio.AnnualInterestRate(nil), rateHistoryBefore(bank.interestRates, first)...)
+		b := bank.withInterestRates(append(rates, path...))
+
+		interest := new(big.Rat)
+		l := loan
+		start := first
+		i := 0
+
+		// Project one month at a time, rounding the loan to whole öre in
+		// between, since exact amounts get ever larger denominators as
+		// interest is capitalized.
+		for ; i < len(sim.Dates); i++ {
+			days, _, err := Project(b, l, start, sim.Dates[i], plan)
+			if err != nil {
+				return Simulation{}, err
+			}
+			for _, day := range days {
+				interest.Add(interest, day.Interest)
+			}
+
+			if len(days) == 0 {
+				break
+			}
+
+			l = roundedLoan(days[len(days)-1].Loan)
+			sim.Balances[i] = append(sim.Balances[i], new(big.Rat).Set(l.balance))
+			sim.Interest[i] = append(sim.Interest[i], new(big.Rat).Set(interest))
+
+			if l.owed().Sign() <= 0 {
+				sim.PaidOff[i]++
+				i++
+				break
+			}
+
+			start = sim.Dates[i].AddDate(0, 0, 1)
+		}
+
+		// The projection ends when the loan is paid off.
+		for ; i < len(sim.Dates); i++ {
+			sim.Balances[i] = append(sim.Balances[i], new(big.Rat))
+			sim.Interest[i] = append(sim.Interest[i], new(big.Rat).Set(interest))
+			sim.PaidOff[i]++
+		}
+	}
+
+	for i := range sim.Dates {
+		for _, values := range [][]*big.Rat{sim.Balances[i], sim.Interest[i]} {
+			sort.Slice(values, func(a, b int) bool {
+				return values[a].Cmp(values[b]) < 0
+			})
+		}
+	}
+
+	return sim, nil
+}
+
+// roundedLoan returns a copy of l with all amounts rounded to whole
+// öre.
+func roundedLoan(l Loan) Loan {
+	r := CopyLoan(l)
+	for _, x := range []*big.Rat{r.balance, r.interest, r.fees, r.overdue, r.paid, r.penalty, r.surplus, r.credit} {
+		x.SetString(x.FloatString(2))
+	}
+	return r
+}
+
+// rateHistoryBefore returns the rates of sorted rates that take effect
+// before day.
+func rateHistoryBefore(rates []intio.AnnualInterestRate, day time.Time) []intio.AnnualInterestRate {
+	i := sort.Search(len(rates), func(i int) bool {
+		return !rates[i].Day.Before(day)
+	})
+	return rates[:i]
+}
+
+// Percentile returns the p-th percentile (0–100) of sorted values by
+// the nearest-rank method.
+func Percentile(sorted []*big.Rat, p float64) *big.Rat {
+	if len(sorted) == 0 {
+		return new(big.Rat)
+	}
+
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	if rank > len(sorted) {
+		rank = len(sorted)
+	}
+
+	return sorted[rank-1]
+}
+
+// RunSimulation runs the calculations like [Run] until today and then
+// simulates the loan from tomorrow until the last day (see
+// [Simulate]) with a model fitted to the interest rate history. The
+// percentile bands of the balance and cumulative interest are written
+// to w as CSV records—one record per month—along with the share of
+// paths in which the loan is paid off. The fitted model is returned.
+func RunSimulation(w io.Writer, firstDay time.Time, principal *big.Rat, bank Bank, plan []intio.PlannedPayment, paths int, seed int64, percentiles []float64, last time.Time, outComma rune) (RateModel, error) {
+	today := DateFromTime(time.Now())
+	days, err := Series(&bank, NewLoan(principal), firstDay, today)
+	if err != nil {
+		return RateModel{}, err
+	}
+	if len(days) == 0 {
+		return RateModel{}, fmt.Errorf("loan starts after today")
+	}
+
+	model, err := FitRateModel(bank.interestRates, today)
+	if err != nil {
+		return RateModel{}, err
+	}
+
+	sim, err := Simulate(bank, days[len(days)-1].Loan, today.AddDate(0, 0, 1), last, plan, model, paths, seed)
+	if err != nil {
+		return RateModel{}, err
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = outComma
+
+	header := []string{"Date"}
+	for _, what := range []string{"Balance", "Cumulative interest"} {
+		for _, p := range percentiles {
+			header = append(header, fmt.Sprintf("%s P%s", what, strconv.FormatFloat(p, 'f', -1, 64)))
+		}
+	}
+	writer.Write(append(header, "Paid off (%)"))
+
+	for i, date := range sim.Dates {
+		record := []string{date.Format(internal.DateLayout)}
+		for _, values := range [][]*big.Rat{sim.Balances[i], sim.Interest[i]} {
+			for _, p := range percentiles {
+				record = append(record, Percentile(values, p).FloatString(2))
+			}
+		}
+		record = append(record, percentOf(big.NewRat(int64(sim.PaidOff[i]), 1), big.NewRat(int64(paths), 1)))
+
+		writer.Write(record)
+	}
+
+	return model, flush(writer)
+}
@@ -0,0 +1,115 @@
+package calc
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+func TestFitRateModel(t *testing.T) {
+	rates := []io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 1, 1, "0.02"),
+	}
+
+	if _, err := FitRateModel(rates, time.Date(2022, 2, 15, 0, 0, 0, 0, time.UTC)); !errors.Is(err, ErrTooLittleHistory) {
+		t.Errorf("want %v, but got %v", ErrTooLittleHistory, err)
+	}
+
+	m, err := FitRateModel(rates, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("fitting model: %s", err)
+	}
+	if want := (RateModel{Mean: 0.02}); want != m {
+		t.Errorf("want %s for constant rates, but got %s", want, m)
+	}
+
+	// A rate that halves its distance to 4 % every month.
+	rates = nil
+	r := 0.0
+	for month := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC); month.Year() < 2023; month = month.AddDate(0, 1, 0) {
+		rate := new(big.Rat).SetFloat64(r)
+		rates = append(rates, io.AnnualInterestRate{Day: month, DecimalRate: rate})
+		r += 0.5 * (0.04 - r)
+	}
+
+	m, err = FitRateModel(rates, time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("fitting model: %s", err)
+	}
+	if !near(m.Reversion, 0.5) || !near(m.Mean, 0.04) || !near(m.Volatility, 0) {
+		t.Errorf("want reversion 0.5 and mean 4 %%, but got %s", m)
+	}
+}
+
+func near(a, b float64) bool {
+	return a-b < 1e-9 && b-a < 1e-9
+}
+
+func TestSimulate(t *testing.T) {
+	bank := NewBank(nil, []io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 1, 1, "0.03"),
+	})
+	loan := NewLoan(mustBigRatFromString("10000"))
+	first := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)
+	plan := []io.PlannedPayment{
+		{From: first, Day: 27, Amount: mustBigRatFromString("500")},
+	}
+
+	// Without volatility, every path stays at the mean, which is the
+	// current rate, so all paths equal the plain projection.
+	sim, err := Simulate(bank, loan, first, last, plan, RateModel{Reversion: 0.1, Mean: 0.03}, 5, 1)
+	if err != nil {
+		t.Fatalf("simulating: %s", err)
+	}
+
+	if want, got := 12, len(sim.Dates); want != got {
+		t.Fatalf("want %d dates, but got %d", want, got)
+	}
+
+	days, _ := mustProject(t, bank, loan, first, last, plan)
+	want := days[len(days)-1].Loan.balance
+
+	balances := sim.Balances[len(sim.Dates)-1]
+	if Percentile(balances, 0).Cmp(Percentile(balances, 100)) != 0 {
+		t.Errorf("want identical paths, but got balances from %s to %s",
+			Percentile(balances, 0).FloatString(2), Percentile(balances, 100).FloatString(2))
+	}
+
+	diff := new(big.Rat).Sub(want, Percentile(balances, 50))
+	if diff.Abs(diff).Cmp(big.NewRat(5, 100)) > 0 {
+		t.Errorf("want balance %s, but got %s", want.FloatString(2), Percentile(balances, 50).FloatString(2))
+	}
+
+	// The same seed gives the same simulation.
+	model := RateModel{Reversion: 0.1, Mean: 0.05, Volatility: 0.005}
+	a, _ := Simulate(bank, loan, first, last, plan, model, 5, 42)
+	b, _ := Simulate(bank, loan, first, last, plan, model, 5, 42)
+	for i := range a.Interest[11] {
+		if a.Interest[11][i].Cmp(b.Interest[11][i]) != 0 {
+			t.Fatalf("want the same interest with the same seed, but got %s and %s",
+				a.Interest[11][i].FloatString(2), b.Interest[11][i].FloatString(2))
+		}
+	}
+	if Percentile(a.Interest[11], 0).Cmp(Percentile(a.Interest[11], 100)) == 0 {
+		t.Error("want paths to differ with volatility")
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	values := []*big.Rat{big.NewRat(1, 1), big.NewRat(2, 1), big.NewRat(3, 1), big.NewRat(4, 1)}
+
+	tests := []struct {
+		p    float64
+		want int64
+	}{{0, 1}, {25, 1}, {50, 2}, {51, 3}, {95, 4}, {100, 4}}
+
+	for _, tt := range tests {
+		if got := Percentile(values, tt.p); got.Cmp(big.NewRat(tt.want, 1)) != 0 {
+			t.Errorf("P%v: want %d, but got %s", tt.p, tt.want, got)
+		}
+	}
+}
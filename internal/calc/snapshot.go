@@ -0,0 +1,238 @@
+package calc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"math/big"
+	"os"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+	intio "gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// ErrStaleSnapshot is returned when resuming from a snapshot whose
+// inputs have changed since it was taken.
+var ErrStaleSnapshot = errors.New("inputs have changed since the snapshot was taken")
+
+// Snapshot is the state of a loan at the end of a day, saved so that
+// later runs can resume from it instead of processing the loan from
+// its first day.
+type Snapshot struct {
+	Day  time.Time
+	Loan Loan
+	// Hash is a hash of the inputs that the loan was processed with
+	// up to and including Day, i.e. the first day and principal of the
+	// loan, and the transactions, rates, fees, and other terms of the
+	// bank that apply before the end of Day.
+	Hash string
+}
+
+// NewSnapshot returns a snapshot of day, the state of a loan of
+// principal processed with bank from the first day.
+func NewSnapshot(bank *Bank, first time.Time, principal *big.Rat, day Day) Snapshot {
+	return Snapshot{
+		Day:  DateFromTime(day.Date),
+		Loan: CopyLoan(day.Loan),
+		Hash: bank.inputsHash(first, principal, day.Date),
+	}
+}
+
+// Check returns [ErrStaleSnapshot] unless s was taken of a loan of
+// principal processed with the same inputs of bank from the first day.
+// Inputs that only apply after the day of s may have changed.
+func (s Snapshot) Check(bank *Bank, first time.Time, principal *big.Rat) error {
+	if s.Hash != bank.inputsHash(first, principal, s.Day) {
+		return ErrStaleSnapshot
+	}
+	return nil
+}
+
+// inputsHash returns a hex-encoded SHA-256 hash of the first day and
+// principal of a loan, and of the inputs of b that apply on or before
+// the given day.
+func (b *Bank) inputsHash(first time.Time, principal *big.Rat, through time.Time) string {
+	through = DateFromTime(through)
+	h := sha256.New()
+
+	fmt.Fprintf(h, "loan|%s|%s\n", DateFromTime(first).Format(internal.DateLayout), principal.RatString())
+
+	for _, t := range b.transactions {
+		if DateFromTime(t.Date).After(through) {
+			break
+		}
+		fmt.Fprintf(h, "transaction|%s|%s|%s|%s|%s\n",
+			t.Date.Format(internal.DateLayout), t.Kind, t.Amount.RatString(), t.Type, t.Description)
+	}
+
+	hashRates(h, "rate", b.interestRates, through)
+	hashRates(h, "penalty", b.penaltyRates, through)
+	hashRates(h, "deposit", b.depositRates, through)
+
+	for _, p := range b.fixedRatePeriods {
+		if !p.Start.After(through) {
+			fmt.Fprintf(h, "fixed|%s|%s|%s\n",
+				p.Start.Format(internal.DateLayout), p.End.Format(internal.DateLayout), p.DecimalRate.RatString())
+		}
+	}
+
+	for _, f := range b.fees {
+		if !f.Start.After(through) {
+			fmt.Fprintf(h, "fee|%t|%s|%s|%s\n",
+				f.Monthly, f.Start.Format(internal.DateLayout), f.End.Format(internal.DateLayout), f.Amount.RatString())
+		}
+	}
+
+	for _, p := range b.minimumPayments {
+		if !p.From.After(through) {
+			fmt.Fprintf(h, "minimum|%s|%d|%s\n", p.From.Format(internal.DateLayout), p.Day, p.Amount.RatString())
+		}
+	}
+
+	fmt.Fprintf(h, "overpayment|%s\n", b.overpayment)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// hashRates writes the rates that change on or before the given day
+// to h.
+func hashRates(h hash.Hash, name string, rates []intio.AnnualInterestRate, through time.Time) {
+	for _, r := range rates {
+		if DateFromTime(r.Day).After(through) {
+			break
+		}
+		fmt.Fprintf(h, "%s|%s|%s\n", name, r.Day.Format(internal.DateLayout), r.DecimalRate.RatString())
+	}
+}
+
+// snapshotFile is the JSON representation of a Snapshot. Amounts are
+// exact fractions formatted as by [big.Rat.RatString].
+type snapshotFile struct {
+	Day  string `json:"day"`
+	Hash string `json:"hash"`
+	Loan struct {
+		Balance  string `json:"balance"`
+		Interest string `json:"interest"`
+		Fees     string `json:"fees"`
+		Overdue  string `json:"overdue"`
+		Paid     string `json:"paid"`
+		Penalty  string `json:"penalty"`
+		Surplus  string `json:"surplus"`
+		Credit   string `json:"credit"`
+	} `json:"loan"`
+}
+
+// fields returns the amounts of f paired with the fields of loan that
+// they represent.
+func (f *snapshotFile) fields(loan Loan) []struct {
+	text   *string
+	amount *big.Rat
+} {
+	return []struct {
+		text   *string
+		amount *big.Rat
+	}{
+		{&f.Loan.Balance, loan.balance},
+		{&f.Loan.Interest, loan.interest},
+		{&f.Loan.Fees, loan.fees},
+		{&f.Loan.Overdue, loan.overdue},
+		{&f.Loan.Paid, loan.paid},
+		{&f.Loan.Penalty, loan.penalty},
+		{&f.Loan.Surplus, loan.surplus},
+		{&f.Loan.Credit, loan.credit},
+	}
+}
+
+// WriteSnapshot writes s to a JSON file, replacing any existing file.
+func WriteSnapshot(jsonFilename string, s Snapshot) error {
+	f := snapshotFile{Day: s.Day.Format(internal.DateLayout), Hash: s.Hash}
+	for _, field := range f.fields(CopyLoan(s.Loan)) {
+		*field.text = field.amount.RatString()
+	}
+
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding snapshot JSON: %w", err)
+	}
+
+	if err := os.WriteFile(jsonFilename, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing snapshot: %w", err)
+	}
+	return nil
+}
+
+// ReadSnapshot reads a snapshot from a JSON file written by
+// [WriteSnapshot].
+func ReadSnapshot(jsonFilename string) (Snapshot, error) {
+	file, err := os.Open(jsonFilename)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("opening JSON file: %w", err)
+	}
+	defer file.Close()
+
+	var f snapshotFile
+
+	d := json.NewDecoder(file)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&f); err != nil {
+		return Snapshot{}, fmt.Errorf("decoding snapshot JSON: %w", err)
+	}
+
+	day, err := time.Parse(internal.DateLayout, f.Day)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("parsing snapshot day: %w", err)
+	}
+
+	loan := NewLoan(new(big.Rat))
+	for _, field := range f.fields(loan) {
+		if _, ok := field.amount.SetString(*field.text); !ok {
+			return Snapshot{}, fmt.Errorf("parsing snapshot amount %q", *field.text)
+		}
+	}
+
+	return Snapshot{Day: day, Loan: loan, Hash: f.Hash}, nil
+}
+
+// RunFrom is like [Run], but resumes from snapshot, if not nil, and
+// writes only the days after it. It returns a snapshot of the last day
+// processed, or snapshot itself if there are no days after it. The
+// returned snapshot is the zero Snapshot if no day has been processed
+// at all.
+//
+// [ErrStaleSnapshot] is returned, before anything is written, if the
+// inputs of bank have changed since snapshot was taken (see
+// [Snapshot.Check]).
+func RunFrom(ctx context.Context, w io.Writer, firstDay time.Time, principal *big.Rat, bank Bank, snapshot *Snapshot, outComma rune) (Snapshot, error) {
+	start, loan := firstDay, NewLoan(principal)
+
+	var taken Snapshot
+	if snapshot != nil {
+		if err := snapshot.Check(&bank, firstDay, principal); err != nil {
+			return Snapshot{}, err
+		}
+		start, loan, taken = snapshot.Day.AddDate(0, 0, 1), snapshot.Loan, *snapshot
+	}
+
+	dw := NewDayWriter(w, &bank, outComma)
+
+	var last *Day
+	err := Walk(ctx, &bank, loan, start, time.Now(), func(d Day) error {
+		last = &d
+		return dw.Write(d)
+	})
+	if last != nil {
+		taken = NewSnapshot(&bank, firstDay, principal, *last)
+	}
+	if err != nil {
+		dw.Flush()
+		return taken, err
+	}
+
+	return taken, dw.Flush()
+}
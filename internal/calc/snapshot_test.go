@@ -0,0 +1,152 @@
+package calc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"path"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+func snapshotBank(t *testing.T) Bank {
+	t.Helper()
+
+	transactions, err := io.ReadTransactions(path.Join("..", "testdata", "transactions.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading transactions: %s", err)
+	}
+	rates, err := io.ReadInterestRates(path.Join("..", "testdata", "annual_interest_rates.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading interest rates: %s", err)
+	}
+
+	return NewBank(transactions, rates)
+}
+
+func TestRunFrom_Resume(t *testing.T) {
+	bank := snapshotBank(t)
+	principal := mustBigRatFromString("100000")
+	first := time.Date(2022, 6, 7, 0, 0, 0, 0, time.UTC)
+
+	var full bytes.Buffer
+	last, err := RunFrom(context.Background(), &full, first, principal, bank, nil, ',')
+	if err != nil {
+		t.Fatalf("running from the first day: %s", err)
+	}
+	if want := DateFromTime(time.Now()); !last.Day.Equal(want) {
+		t.Errorf("want snapshot of %s, but got %s", want, last.Day)
+	}
+
+	days := mustSeries(t, &bank, NewLoan(principal), first, time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC))
+	snapshot := NewSnapshot(&bank, first, principal, days[len(days)-1])
+
+	var resumed bytes.Buffer
+	if _, err := RunFrom(context.Background(), &resumed, first, principal, bank, &snapshot, ','); err != nil {
+		t.Fatalf("resuming from snapshot: %s", err)
+	}
+
+	// The resumed run writes the header and the days after the
+	// snapshot, which are the same as those of the full run.
+	fullLines := strings.Split(full.String(), "\n")
+	resumedLines := strings.Split(resumed.String(), "\n")
+
+	if want, got := fullLines[0], resumedLines[0]; want != got {
+		t.Errorf("want header %q, but got %q", want, got)
+	}
+	if want, got := strings.Join(fullLines[1+len(days):], "\n"), strings.Join(resumedLines[1:], "\n"); want != got {
+		t.Errorf("want resumed days\n%s\nbut got\n%s", want, got)
+	}
+}
+
+func TestSnapshot_Check(t *testing.T) {
+	principal := mustBigRatFromString("100000")
+	first := time.Date(2022, 6, 7, 0, 0, 0, 0, time.UTC)
+	day := time.Date(2022, 9, 30, 0, 0, 0, 0, time.UTC)
+
+	bank := snapshotBank(t)
+	days := mustSeries(t, &bank, NewLoan(principal), first, day)
+	snapshot := NewSnapshot(&bank, first, principal, days[len(days)-1])
+
+	tests := []struct {
+		name    string
+		change  func(transactions []io.Transaction) []io.Transaction
+		wantErr error
+	}{
+		{
+			name:   "unchanged",
+			change: func(transactions []io.Transaction) []io.Transaction { return transactions },
+		},
+		{
+			name: "new transaction after snapshot",
+			change: func(transactions []io.Transaction) []io.Transaction {
+				return append(transactions, io.MustNewTransaction(2022, 10, 3, "1000"))
+			},
+		},
+		{
+			name: "new transaction before snapshot",
+			change: func(transactions []io.Transaction) []io.Transaction {
+				return append(transactions, io.MustNewTransaction(2022, 9, 30, "1000"))
+			},
+			wantErr: ErrStaleSnapshot,
+		},
+		{
+			name: "changed transaction before snapshot",
+			change: func(transactions []io.Transaction) []io.Transaction {
+				transactions[0].Amount = mustBigRatFromString("1")
+				return transactions
+			},
+			wantErr: ErrStaleSnapshot,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transactions := append([]io.Transaction(nil), bank.transactions...)
+			changed := bank.withTransactions(tt.change(transactions))
+
+			if err := snapshot.Check(&changed, first, principal); !errors.Is(err, tt.wantErr) {
+				t.Errorf("want error %v, but got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	if err := snapshot.Check(&bank, first, mustBigRatFromString("100001")); !errors.Is(err, ErrStaleSnapshot) {
+		t.Errorf("want stale snapshot for another principal, but got %v", err)
+	}
+}
+
+func TestWriteSnapshot(t *testing.T) {
+	loan := NewLoan(mustBigRatFromString("100000"))
+	loan.interest.SetFrac64(1, 3)
+	loan.surplus.SetFrac64(-2, 7)
+
+	want := Snapshot{
+		Day:  time.Date(2022, 9, 30, 0, 0, 0, 0, time.UTC),
+		Loan: loan,
+		Hash: "abc",
+	}
+
+	filename := path.Join(t.TempDir(), "snapshot.json")
+	if err := WriteSnapshot(filename, want); err != nil {
+		t.Fatalf("writing snapshot: %s", err)
+	}
+
+	got, err := ReadSnapshot(filename)
+	if err != nil {
+		t.Fatalf("reading snapshot: %s", err)
+	}
+
+	if !want.Day.Equal(got.Day) || want.Hash != got.Hash {
+		t.Errorf("want snapshot of %s with hash %q, but got %s with %q", want.Day, want.Hash, got.Day, got.Hash)
+	}
+	if want, got := loan.owed(), got.Loan.owed(); want.Cmp(got) != 0 {
+		t.Errorf("want owed %s, but got %s", want, got)
+	}
+	if want, got := loan.interest, got.Loan.interest; want.Cmp(got) != 0 {
+		t.Errorf("want interest %s, but got %s", want, got)
+	}
+}
@@ -0,0 +1,119 @@
+package calc
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+	intio "gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// Stress is the outcome of projecting a loan with its floating
+// interest rates shifted.
+type Stress struct {
+	// Shift is the shift of the interest rates in decimal form.
+	Shift *big.Rat
+	// MonthlyInterest is the interest accrued during the first full
+	// calendar month of the projection.
+	MonthlyInterest *big.Rat
+	// PaidOff is the day the loan is paid off, or the zero time if it
+	// is not paid off by the last day.
+	PaidOff time.Time
+	// TotalInterest is the interest accrued during the projection.
+	TotalInterest *big.Rat
+}
+
+// StressTest projects loan with bank and plan from the first through
+// the last day (see [Project]) once for each shift of the interest
+// rates from the first day on. Fixed-rate periods are not shifted.
+func StressTest(bank Bank, loan Loan, first, last time.Time, plan []intio.PlannedPayment, shifts []*big.Rat) ([]Stress, error) {
+	first = DateFromTime(first)
+
+	monthStart := time.Date(first.Year(), first.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if !monthStart.Equal(first) {
+		monthStart = monthStart.AddDate(0, 1, 0)
+	}
+	monthEnd := monthStart.AddDate(0, 1, 0)
+
+	results := make([]Stress, len(shifts))
+
+	for i, shift := range shifts {
+		shock := intio.Scenario{RateShocks: []intio.RateShock{{From: first, Delta: shift}}}
+		shifted := bank.withInterestRates(shock.ApplyInterestRates(bank.interestRates))
+
+		days, _, err := Project(shifted, loan, first, last, plan)
+		if err != nil {
+			return nil, fmt.Errorf("rate shift %s pp: %w",
+				new(big.Rat).Mul(shift, big.NewRat(100, 1)).FloatString(2), err)
+		}
+
+		s := Stress{
+			Shift:           new(big.Rat).Set(shift),
+			MonthlyInterest: new(big.Rat),
+			TotalInterest:   new(big.Rat),
+		}
+
+		for _, day := range days {
+			s.TotalInterest.Add(s.TotalInterest, day.Interest)
+			if !day.Date.Before(monthStart) && day.Date.Before(monthEnd) {
+				s.MonthlyInterest.Add(s.MonthlyInterest, day.Interest)
+			}
+			if day.PaidOff {
+				s.PaidOff = day.Date
+			}
+		}
+
+		results[i] = s
+	}
+
+	return results, nil
+}
+
+// RunStressTest runs the calculations like [Run] until today and then
+// stress tests the loan from tomorrow until the last day with the
+// interest rates shifted by each of shifts (see [StressTest]). The
+// outcome is written to w as CSV records—one record per shift.
+func RunStressTest(w io.Writer, firstDay time.Time, principal *big.Rat, bank Bank, plan []intio.PlannedPayment, shifts []*big.Rat, last time.Time, outComma rune) error {
+	today := DateFromTime(time.Now())
+	days, err := Series(&bank, NewLoan(principal), firstDay, today)
+	if err != nil {
+		return err
+	}
+	if len(days) == 0 {
+		return fmt.Errorf("loan starts after today")
+	}
+
+	results, err := StressTest(bank, days[len(days)-1].Loan, today.AddDate(0, 0, 1), last, plan, shifts)
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = outComma
+
+	writer.Write([]string{
+		"Rate shift (pp)",
+		"Monthly interest",
+		"Payoff date",
+		"Total interest",
+	})
+
+	for _, s := range results {
+		paidOff := "-"
+		if !s.PaidOff.IsZero() {
+			paidOff = s.PaidOff.Format(internal.DateLayout)
+		}
+
+		writer.Write([]string{
+			new(big.Rat).Mul(s.Shift, big.NewRat(100, 1)).FloatString(2),
+			s.MonthlyInterest.FloatString(2),
+			paidOff,
+			s.TotalInterest.FloatString(2),
+		})
+	}
+
+	return flush(writer)
+}
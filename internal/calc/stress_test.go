@@ -0,0 +1,64 @@
+package calc
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+func TestStressTest(t *testing.T) {
+	bank := NewBank(nil, []io.AnnualInterestRate{
+		io.MustNewAnnualInterestRate(2022, 1, 1, "0.03"),
+	})
+	loan := NewLoan(mustBigRatFromString("10000"))
+	first := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(2030, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	plan := []io.PlannedPayment{
+		{From: first, Day: 27, Amount: mustBigRatFromString("1000")},
+	}
+
+	results, err := StressTest(bank, loan, first, last, plan, []*big.Rat{
+		new(big.Rat),
+		big.NewRat(3, 100),
+	})
+	if err != nil {
+		t.Fatalf("stress testing: %s", err)
+	}
+
+	if want, got := 2, len(results); want != got {
+		t.Fatalf("want %d results, but got %d", want, got)
+	}
+
+	// Without a shift, the outcome is that of the plain projection.
+	days, _ := mustProject(t, bank, loan, first, last, plan)
+	total := new(big.Rat)
+	for _, d := range days {
+		total.Add(total, d.Interest)
+	}
+
+	base, shifted := results[0], results[1]
+
+	if want, got := total, base.TotalInterest; want.Cmp(got) != 0 {
+		t.Errorf("want total interest %s, but got %s", want.FloatString(2), got.FloatString(2))
+	}
+	if want, got := days[len(days)-1].Date, base.PaidOff; !want.Equal(got) {
+		t.Errorf("want payoff on %s, but got %s", want, got)
+	}
+
+	// The balance is the same throughout January, so doubling the
+	// rate doubles the interest.
+	if want, got := new(big.Rat).Mul(base.MonthlyInterest, big.NewRat(2, 1)), shifted.MonthlyInterest; want.Cmp(got) != 0 {
+		t.Errorf("want shifted January interest %s, but got %s", want.FloatString(2), got.FloatString(2))
+	}
+
+	if shifted.TotalInterest.Cmp(base.TotalInterest) <= 0 {
+		t.Errorf("want more interest with higher rates, but got %s and %s",
+			shifted.TotalInterest.FloatString(2), base.TotalInterest.FloatString(2))
+	}
+	if shifted.PaidOff.Before(base.PaidOff) {
+		t.Errorf("want payoff no earlier with higher rates, but got %s", shifted.PaidOff)
+	}
+}
@@ -0,0 +1,121 @@
+package calc
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+)
+
+// Summary sums up a loan over a period of days.
+type Summary struct {
+	// From and To are the first and last days of the period.
+	From, To time.Time
+	// Payments is the sum of the payments made.
+	Payments *big.Rat
+	// Interest is the interest accrued.
+	Interest *big.Rat
+	// Fees is the sum of the fees charged.
+	Fees *big.Rat
+	// PenaltyInterest is the penalty interest accrued on overdue
+	// payments.
+	PenaltyInterest *big.Rat
+}
+
+// Cost returns the cost of the loan over the period, i.e. the interest
+// plus the fees and penalty interest.
+func (s Summary) Cost() *big.Rat {
+	cost := new(big.Rat).Add(s.Interest, s.Fees)
+	return cost.Add(cost, s.PenaltyInterest)
+}
+
+// Summarize sums up days processed by bank, which must not be empty.
+func Summarize(bank *Bank, days []Day) Summary {
+	s := Summary{
+		From:     days[0].Date,
+		To:       days[len(days)-1].Date,
+		Payments: new(big.Rat),
+		Interest: new(big.Rat),
+		Fees:     new(big.Rat),
+
+		PenaltyInterest: new(big.Rat),
+	}
+
+	for _, day := range days {
+		s.Payments.Add(s.Payments, bank.paymentsAmount(day.Date))
+		s.Interest.Add(s.Interest, day.Interest)
+		s.Fees.Add(s.Fees, day.Fees)
+		s.PenaltyInterest.Add(s.PenaltyInterest, day.PenaltyInterest)
+	}
+
+	return s
+}
+
+// Yearly sums up days processed by bank per calendar year.
+func Yearly(bank *Bank, days []Day) []Summary {
+	var years []Summary
+
+	for start := 0; start < len(days); {
+		end := start
+		for end < len(days) && days[end].Date.Year() == days[start].Date.Year() {
+			end++
+		}
+
+		years = append(years, Summarize(bank, days[start:end]))
+		start = end
+	}
+
+	return years
+}
+
+// RunCost runs the calculations like [Run], but writes the payments,
+// interest, fees, penalty interest, and total cost of the loan to w as
+// CSV records—one record per year followed by one for the whole loan.
+func RunCost(w io.Writer, firstDay time.Time, principal *big.Rat, bank Bank, outComma rune) error {
+	days, err := Series(&bank, NewLoan(principal), firstDay, time.Now())
+	if err != nil {
+		return err
+	}
+
+	writer := csv.NewWriter(w)
+	writer.Comma = outComma
+
+	writer.Write([]string{
+		"Period",
+		"From",
+		"To",
+		"Payments",
+		"Interest",
+		"Fees",
+		"Penalty interest",
+		"Total cost",
+	})
+
+	if len(days) == 0 {
+		return flush(writer)
+	}
+
+	write := func(period string, s Summary) {
+		writer.Write([]string{
+			period,
+			s.From.Format(internal.DateLayout),
+			s.To.Format(internal.DateLayout),
+			s.Payments.FloatString(2),
+			s.Interest.FloatString(2),
+			s.Fees.FloatString(2),
+			s.PenaltyInterest.FloatString(2),
+			s.Cost().FloatString(2),
+		})
+	}
+
+	for _, s := range Yearly(&bank, days) {
+		write(fmt.Sprint(s.From.Year()), s)
+	}
+
+	write("Total", Summarize(&bank, days))
+
+	return flush(writer)
+}
@@ -0,0 +1,106 @@
+package calc
+
+import (
+	"bytes"
+	"encoding/csv"
+	"path"
+	"testing"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+func TestSummarize_Fees(t *testing.T) {
+	transactions, err := io.ReadTransactions(path.Join("..", "testdata", "transactions.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading transactions: %s", err)
+	}
+
+	rates, err := io.ReadInterestRates(path.Join("..", "testdata", "annual_interest_rates.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading interest rates: %s", err)
+	}
+
+	fees, err := io.ReadFees(path.Join("..", "testdata", "fees.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading fees: %s", err)
+	}
+
+	bank := NewBank(transactions, rates)
+	bank.AddFees(fees...)
+
+	firstDay := time.Date(2022, time.June, 7, 0, 0, 0, 0, time.UTC)
+	days := mustSeries(t, &bank, NewLoan(mustBigRatFromString("100000")), firstDay,
+		time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC))
+
+	wantDateFees := map[string]string{
+		"2022-06-07": "500.00",
+		"2022-07-28": "558.00",
+		"2022-08-10": "0.00",
+		"2022-08-28": "29.00",
+	}
+
+	for _, day := range days {
+		date := day.Date.Format("2006-01-02")
+		if want, ok := wantDateFees[date]; ok {
+			if got := day.Loan.fees.FloatString(2); want != got {
+				t.Errorf("%s: want unpaid fees %s, but got %s", date, want, got)
+			}
+		}
+	}
+
+	// The payment on 2022-08-10 settles the unpaid fees first.
+	if day, _ := dayOf(days, time.Date(2022, 8, 10, 0, 0, 0, 0, time.UTC)); day.Loan.balance.FloatString(2) != "97760.14" {
+		t.Errorf("want balance 97760.14, but got %s", day.Loan.balance.FloatString(2))
+	}
+
+	s := Summarize(&bank, days)
+
+	if want, got := "7403.90", s.Payments.FloatString(2); want != got {
+		t.Errorf("want payments %s, but got %s", want, got)
+	}
+
+	if want, got := "703.00", s.Fees.FloatString(2); want != got {
+		t.Errorf("want fees %s, but got %s", want, got)
+	}
+
+	if want, got := s.Cost(), s.Interest; want.Cmp(got) <= 0 {
+		t.Errorf("want cost %s above interest %s", want.FloatString(2), got.FloatString(2))
+	}
+}
+
+func TestRunCost(t *testing.T) {
+	transactions, err := io.ReadTransactions(path.Join("..", "testdata", "transactions.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading transactions: %s", err)
+	}
+
+	rates, err := io.ReadInterestRates(path.Join("..", "testdata", "annual_interest_rates.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading interest rates: %s", err)
+	}
+
+	firstDay := time.Date(2022, time.June, 7, 0, 0, 0, 0, time.UTC)
+
+	var outCSV bytes.Buffer
+	if err := RunCost(&outCSV, firstDay, mustBigRatFromString("100000"), NewBank(transactions, rates), ','); err != nil {
+		t.Fatalf("running cost: %s", err)
+	}
+
+	records, err := csv.NewReader(&outCSV).ReadAll()
+	if err != nil {
+		t.Fatalf("reading CSV output: %s", err)
+	}
+
+	if want, got := time.Now().Year()-2022+3, len(records); want != got {
+		t.Fatalf("want %d records, but got %d", want, got)
+	}
+
+	if want, got := []string{"2022", "2022-06-07", "2022-12-31", "7403.90"}, records[1][:4]; !equalStrings(want, got) {
+		t.Errorf("want %v, but got %v", want, got)
+	}
+
+	if want, got := "Total", records[len(records)-1][0]; want != got {
+		t.Errorf("want %q, but got %q", want, got)
+	}
+}
@@ -0,0 +1,331 @@
+// Package input loads the inputs of a loan from the files and values
+// given on the command line or in a configuration file.
+package input
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/calc"
+	intio "gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// defaultPenaltyAddition is the addition, in percentage points, to the
+// reference rate that makes the penalty interest rate (dröjsmålsränta)
+// according to the Swedish Interest Act (räntelagen).
+const defaultPenaltyAddition = "8"
+
+// Flags are the inputs of a loan as given on the command line (see
+// [Flags.Register]) or in a configuration file: the names of the files
+// to read them from, and their unparsed values.
+type Flags struct {
+	Transactions  string `json:"transactions"`  // -t flag
+	Ledger        string `json:"ledger"`        // -l flag
+	KindRules     string `json:"kindRules"`     // -k flag
+	InterestRates string `json:"interestRates"` // -r flag
+	FirstDay      string `json:"firstDay"`      // -d flag
+	Principal     string `json:"principal"`     // -p flag
+	InComma       string `json:"comma"`         // -n flag
+	OutComma      string `json:"-"`             // -u flag
+
+	ReferenceRates string `json:"referenceRates"` // -R flag
+	Margins        string `json:"margins"`        // -m flag
+	ResetMonths    int    `json:"resetMonths"`    // -f flag
+	Floor          bool   `json:"floor"`          // -z flag
+
+	FixedRatePeriods string `json:"fixedRatePeriods"` // -x flag
+	Fees             string `json:"fees"`             // -F flag
+
+	MinimumPayments string `json:"minimumPayments"` // -M flag
+	PenaltyRates    string `json:"penaltyRates"`    // -L flag
+	PenaltyAddition string `json:"penaltyAddition"` // -A flag
+
+	Overpayment  string `json:"overpayment"`  // -o flag
+	DepositRates string `json:"depositRates"` // -D flag
+
+	Scenario string `json:"scenario"` // -S flag
+
+	// WithoutRates leaves out the -r flag for commands that do not
+	// need any interest rates.
+	WithoutRates bool `json:"-"`
+}
+
+// DefaultFlags returns the default values of the flags.
+func DefaultFlags() Flags {
+	return Flags{
+		Transactions:    "transactions.csv",
+		InterestRates:   "interest_rates.csv",
+		FirstDay:        "2022-06-27",
+		Principal:       "200000",
+		InComma:         ";",
+		OutComma:        ";",
+		ResetMonths:     3,
+		Floor:           true,
+		PenaltyAddition: defaultPenaltyAddition,
+		Overpayment:     "surplus",
+	}
+}
+
+// Resolve makes the relative file names of f relative to dir.
+func (f *Flags) Resolve(dir string) {
+	for _, name := range []*string{
+		&f.Transactions, &f.Ledger, &f.KindRules, &f.InterestRates,
+		&f.ReferenceRates, &f.Margins, &f.FixedRatePeriods, &f.Fees,
+		&f.MinimumPayments, &f.PenaltyRates, &f.DepositRates, &f.Scenario,
+	} {
+		if *name != "" && !filepath.IsAbs(*name) {
+			*name = filepath.Join(dir, *name)
+		}
+	}
+}
+
+// Register defines the flags in fs, with the default values of
+// [DefaultFlags].
+func (f *Flags) Register(fs *flag.FlagSet) {
+	def := DefaultFlags()
+
+	fs.StringVar(&f.Transactions, "t", def.Transactions, "transactions CSV `file`")
+	fs.StringVar(&f.Ledger, "l", def.Ledger, "ledger `file` to read the transactions from instead of -t (see the import command)")
+	fs.StringVar(&f.KindRules, "k", def.KindRules, "transaction kind rules CSV `file` applied before the default rules")
+	if !f.WithoutRates {
+		fs.StringVar(&f.InterestRates, "r", def.InterestRates, "interest rates CSV `file`")
+		fs.StringVar(&f.ReferenceRates, "R", def.ReferenceRates, "reference rates CSV `file` used with -m instead of -r")
+		fs.StringVar(&f.Margins, "m", def.Margins, "margins CSV `file` added to the reference rates")
+		fs.IntVar(&f.ResetMonths, "f", def.ResetMonths, "`months` between interest rate resets with -R")
+		fs.BoolVar(&f.Floor, "z", def.Floor, "floor the reference rate at zero with -R")
+		fs.StringVar(&f.FixedRatePeriods, "x", def.FixedRatePeriods, "fixed-rate periods CSV `file`")
+	}
+	fs.StringVar(&f.Fees, "F", def.Fees, "fee schedule CSV `file`")
+	fs.StringVar(&f.MinimumPayments, "M", def.MinimumPayments, "minimum payments (payment plan) CSV `file`")
+	fs.StringVar(&f.PenaltyRates, "L", def.PenaltyRates, "reference rates CSV `file` for penalty interest on overdue payments")
+	fs.StringVar(&f.PenaltyAddition, "A", def.PenaltyAddition, "`percentage points` added to the reference rates with -L")
+	fs.StringVar(&f.Overpayment, "o", def.Overpayment, "overpayment `policy`: surplus, credit, or error")
+	fs.StringVar(&f.DepositRates, "D", def.DepositRates, "deposit rates CSV `file` for a credit with -o credit")
+	fs.StringVar(&f.Scenario, "S", def.Scenario, "scenario JSON `file` overlaid on the inputs")
+	fs.StringVar(&f.FirstDay, "d", def.FirstDay, "`date` of first day of loan")
+	fs.StringVar(&f.Principal, "p", def.Principal, "principal `balance` on first day")
+	fs.StringVar(&f.InComma, "n", def.InComma, "input CSV file field delimiter `character` ")
+	fs.StringVar(&f.OutComma, "u", def.OutComma, "output CSV file field delimiter `character` ")
+}
+
+// Inputs are the parsed and loaded values of [Flags].
+type Inputs struct {
+	FirstDay      time.Time
+	Principal     *big.Rat
+	Transactions  []intio.Transaction
+	InterestRates []intio.AnnualInterestRate
+	InComma       rune
+	OutComma      rune
+
+	FixedRatePeriods []intio.FixedRatePeriod
+	Fees             []intio.ScheduledFee
+
+	MinimumPayments []intio.PlannedPayment
+	PenaltyRates    []intio.AnnualInterestRate
+	PenaltyAddition *big.Rat
+
+	Overpayment  calc.OverpaymentPolicy
+	DepositRates []intio.AnnualInterestRate
+
+	Scenario intio.Scenario
+}
+
+// ReadPlan reads a payment plan from file, unless file is empty, and
+// applies the plan changes of the scenario to it.
+func (in Inputs) ReadPlan(file string) ([]intio.PlannedPayment, error) {
+	var plan []intio.PlannedPayment
+
+	if file != "" {
+		var err error
+		plan, err = intio.ReadPaymentPlan(file, in.InComma)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read payment plan: %w", err)
+		}
+	}
+
+	return in.Scenario.ApplyPlan(plan), nil
+}
+
+// NewBank returns a bank holding the loaded inputs.
+func (in Inputs) NewBank() calc.Bank {
+	bank := calc.NewBank(in.Transactions, in.InterestRates)
+	bank.AddFixedRatePeriods(in.FixedRatePeriods...)
+	bank.AddFees(in.Fees...)
+	bank.SetMinimumPayments(in.MinimumPayments)
+	if in.PenaltyRates != nil {
+		bank.SetPenaltyInterest(in.PenaltyRates, in.PenaltyAddition)
+	}
+	bank.SetOverpaymentPolicy(in.Overpayment, in.DepositRates)
+	return bank
+}
+
+// Load parses the values of f and reads its files.
+func (f *Flags) Load() (Inputs, error) {
+	var in Inputs
+	var err error
+
+	in.InComma, err = ParseComma(f.InComma)
+	if err != nil {
+		return in, fmt.Errorf("failed to get input CSV file field delimiter character: %w", err)
+	}
+
+	in.OutComma, err = ParseComma(f.OutComma)
+	if err != nil {
+		return in, fmt.Errorf("failed to get output CSV file field delimiter character: %w", err)
+	}
+
+	in.FirstDay, err = time.Parse(internal.DateLayout, f.FirstDay)
+	if err != nil {
+		return in, fmt.Errorf("failed to read first day argument: %w", err)
+	}
+
+	var ok bool
+	in.Principal, ok = new(big.Rat).SetString(f.Principal)
+	if !ok {
+		return in, fmt.Errorf("failed to parse principal balance %q", f.Principal)
+	}
+
+	in.Transactions, err = ReadTransactions(f.Transactions, f.Ledger, f.KindRules, in.InComma)
+	if err != nil {
+		return in, err
+	}
+
+	if f.Scenario != "" {
+		in.Scenario, err = intio.ReadScenario(f.Scenario)
+		if err != nil {
+			return in, fmt.Errorf("failed to read scenario: %w", err)
+		}
+		in.Transactions = in.Scenario.ApplyTransactions(in.Transactions)
+	}
+
+	if f.Fees != "" {
+		in.Fees, err = intio.ReadFees(f.Fees, in.InComma)
+		if err != nil {
+			return in, fmt.Errorf("failed to read fees: %w", err)
+		}
+	}
+
+	if f.MinimumPayments != "" {
+		in.MinimumPayments, err = intio.ReadPaymentPlan(f.MinimumPayments, in.InComma)
+		if err != nil {
+			return in, fmt.Errorf("failed to read minimum payments: %w", err)
+		}
+	}
+
+	if f.PenaltyRates != "" {
+		in.PenaltyRates, err = intio.ReadInterestRates(f.PenaltyRates, in.InComma)
+		if err != nil {
+			return in, fmt.Errorf("failed to read penalty reference rates: %w", err)
+		}
+
+		in.PenaltyAddition, ok = new(big.Rat).SetString(f.PenaltyAddition)
+		if !ok {
+			return in, fmt.Errorf("failed to parse penalty interest addition %q", f.PenaltyAddition)
+		}
+		in.PenaltyAddition.Quo(in.PenaltyAddition, big.NewRat(100, 1))
+	}
+
+	in.Overpayment, err = calc.ParseOverpaymentPolicy(f.Overpayment)
+	if err != nil {
+		return in, fmt.Errorf("failed to parse overpayment policy: %w", err)
+	}
+
+	if f.DepositRates != "" {
+		in.DepositRates, err = intio.ReadInterestRates(f.DepositRates, in.InComma)
+		if err != nil {
+			return in, fmt.Errorf("failed to read deposit rates: %w", err)
+		}
+	}
+
+	if f.WithoutRates {
+		return in, nil
+	}
+
+	if f.FixedRatePeriods != "" {
+		in.FixedRatePeriods, err = intio.ReadFixedRatePeriods(f.FixedRatePeriods, in.InComma)
+		if err != nil {
+			return in, fmt.Errorf("failed to read fixed-rate periods: %w", err)
+		}
+	}
+
+	if f.ReferenceRates == "" {
+		in.InterestRates, err = intio.ReadInterestRates(f.InterestRates, in.InComma)
+		if err != nil {
+			return in, fmt.Errorf("failed to read interest rates: %w", err)
+		}
+		in.InterestRates = in.Scenario.ApplyInterestRates(in.InterestRates)
+		return in, nil
+	}
+
+	pricing := calc.ReferencePricing{ResetMonths: f.ResetMonths, Floor: f.Floor}
+
+	pricing.Reference, err = intio.ReadInterestRates(f.ReferenceRates, in.InComma)
+	if err != nil {
+		return in, fmt.Errorf("failed to read reference rates: %w", err)
+	}
+
+	if f.Margins != "" {
+		pricing.Margins, err = intio.ReadInterestRates(f.Margins, in.InComma)
+		if err != nil {
+			return in, fmt.Errorf("failed to read margins: %w", err)
+		}
+	}
+
+	in.InterestRates, err = pricing.Rates(in.FirstDay)
+	if err != nil {
+		return in, fmt.Errorf("failed to calculate interest rates from reference rates: %w", err)
+	}
+	in.InterestRates = in.Scenario.ApplyInterestRates(in.InterestRates)
+
+	return in, nil
+}
+
+// ReadTransactions reads transactions from the ledger file, or from the
+// transactions CSV file if ledger is empty, and classifies them with
+// the kind rules read from the kindRules file, unless it is empty,
+// followed by the default rules. The kinds of ledger transactions that
+// were given when they were added are kept. A ledger that does not
+// exist yet has no transactions.
+func ReadTransactions(transactions, ledger, kindRules string, comma rune) ([]intio.Transaction, error) {
+	rules := intio.DefaultKindRules
+	if kindRules != "" {
+		r, err := intio.ReadKindRules(kindRules, comma)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read transaction kind rules: %w", err)
+		}
+		rules = append(r, intio.DefaultKindRules...)
+	}
+
+	if ledger != "" {
+		entries, err := intio.ReadLedger(ledger)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read ledger: %w", err)
+		}
+		return intio.LedgerTransactions(entries, rules), nil
+	}
+
+	transactionsL, err := intio.ReadTransactions(transactions, comma)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transactions: %w", err)
+	}
+	intio.Classify(transactionsL, rules)
+
+	return transactionsL, nil
+}
+
+// ParseComma returns the CSV field delimiter of csvComma, which must be
+// a single character.
+func ParseComma(csvComma string) (rune, error) {
+	comma := []rune(csvComma)
+	if len := len(comma); len != 1 {
+		return rune(0), errors.New("must be a single character")
+	} else {
+		return comma[0], nil
+	}
+}
@@ -0,0 +1,149 @@
+package io
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"regexp"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+)
+
+// Borrower is one of several borrowers sharing a loan.
+type Borrower struct {
+	Name string
+	// Share is the borrower's ownership share, as a decimal, of the
+	// loan. It is also used to split transactions that cannot be
+	// attributed to a single borrower.
+	Share *big.Rat
+	// Pattern matches the descriptions of transactions made by the
+	// borrower, or is nil if no transactions are attributed by their
+	// descriptions.
+	Pattern *regexp.Regexp
+}
+
+// ReadBorrowers reads borrowers from a CSV file with a header line
+// followed by records of name, ownership share in percent, and
+// (optionally) a regular expression matching the descriptions of the
+// borrower's transactions.
+func ReadBorrowers(csvFilename string, comma rune) ([]Borrower, error) {
+	file, err := os.Open(csvFilename)
+	if err != nil {
+		return nil, fmt.Errorf("opening CSV file: %w", err)
+	}
+	defer file.Close()
+
+	borrowers := []Borrower{}
+	bigRat100 := big.NewRat(100, 1)
+
+	r := csv.NewReader(file)
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+
+	if _, err := r.Read(); err != nil { // skip first line
+		return nil, fmt.Errorf("reading borrower CSV header: %w", err)
+	}
+
+	for {
+		r, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading borrower CSV record: %w", err)
+		}
+		if len(r) < 2 {
+			return nil, fmt.Errorf("borrower CSV record has %d field(s), want at least 2", len(r))
+		}
+
+		rName, rShare, rPattern := r[0], r[1], ""
+		if len(r) > 2 {
+			rPattern = r[2]
+		}
+
+		share, err := ParseAmount(rShare)
+		if err != nil {
+			return nil, fmt.Errorf("parsing share %q: %w", rShare, err)
+		}
+
+		var pattern *regexp.Regexp
+		if rPattern != "" {
+			pattern, err = regexp.Compile(rPattern)
+			if err != nil {
+				return nil, fmt.Errorf("compiling pattern of borrower %q: %w", rName, err)
+			}
+		}
+
+		borrowers = append(borrowers, Borrower{
+			Name:    rName,
+			Share:   share.Quo(share, bigRat100),
+			Pattern: pattern,
+		})
+	}
+
+	return borrowers, nil
+}
+
+// Attribution attributes the transaction with the given date and
+// amount to a borrower.
+type Attribution struct {
+	Date     time.Time
+	Amount   *big.Rat
+	Borrower string
+}
+
+// ReadAttributions reads attributions from a CSV file with a header
+// line followed by records of date, amount, and borrower name.
+func ReadAttributions(csvFilename string, comma rune) ([]Attribution, error) {
+	file, err := os.Open(csvFilename)
+	if err != nil {
+		return nil, fmt.Errorf("opening CSV file: %w", err)
+	}
+	defer file.Close()
+
+	attributions := []Attribution{}
+
+	r := csv.NewReader(file)
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+
+	if _, err := r.Read(); err != nil { // skip first line
+		return nil, fmt.Errorf("reading attribution CSV header: %w", err)
+	}
+
+	for {
+		r, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading attribution CSV record: %w", err)
+		}
+		if len(r) < 3 {
+			return nil, fmt.Errorf("attribution CSV record has %d field(s), want at least 3", len(r))
+		}
+
+		rDate, rAmount, rBorrower := r[0], r[1], r[2]
+
+		date, err := time.Parse(internal.DateLayout, rDate)
+		if err != nil {
+			return nil, fmt.Errorf("parsing date: %w", err)
+		}
+
+		amount, err := ParseAmount(rAmount)
+		if err != nil {
+			return nil, fmt.Errorf("parsing amount %q: %w", rAmount, err)
+		}
+
+		attributions = append(attributions, Attribution{
+			Date:     date,
+			Amount:   amount,
+			Borrower: rBorrower,
+		})
+	}
+
+	return attributions, nil
+}
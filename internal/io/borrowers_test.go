@@ -0,0 +1,66 @@
+package io
+
+import (
+	"path"
+	"testing"
+	"time"
+)
+
+func TestReadBorrowers(t *testing.T) {
+	borrowers, err := ReadBorrowers(path.Join("..", "testdata", "borrowers.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading borrowers: %s", err)
+	}
+
+	if want, got := 2, len(borrowers); want != got {
+		t.Fatalf("want %d borrowers, but got %d", want, got)
+	}
+
+	if want, got := "Anna", borrowers[0].Name; want != got {
+		t.Errorf("want name %q, but got %q", want, got)
+	}
+
+	if want, got := "0.50", borrowers[0].Share.FloatString(2); want != got {
+		t.Errorf("want share %s, but got %s", want, got)
+	}
+
+	if !borrowers[0].Pattern.MatchString("RÄNTA+AMOR") {
+		t.Errorf("want pattern %s to match", borrowers[0].Pattern)
+	}
+
+	if borrowers[1].Pattern.MatchString("RÄNTA+AMOR") {
+		t.Errorf("want pattern %s not to match", borrowers[1].Pattern)
+	}
+}
+
+func TestReadAttributions(t *testing.T) {
+	attributions, err := ReadAttributions(path.Join("..", "testdata", "attributions.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading attributions: %s", err)
+	}
+
+	if want, got := 1, len(attributions); want != got {
+		t.Fatalf("want %d attributions, but got %d", want, got)
+	}
+
+	a := attributions[0]
+
+	if want, got := time.Date(2022, 11, 3, 0, 0, 0, 0, time.UTC), a.Date; want != got {
+		t.Errorf("want date %v, but got %v", want, got)
+	}
+
+	if want, got := "1300.00", a.Amount.FloatString(2); want != got {
+		t.Errorf("want amount %s, but got %s", want, got)
+	}
+
+	if want, got := "Anna", a.Borrower; want != got {
+		t.Errorf("want borrower %q, but got %q", want, got)
+	}
+}
+
+func TestReadAttributions_ShortRecord(t *testing.T) {
+	_, err := ReadAttributions(writeCSV(t, "Date;Amount\n2022-07-27;3000\n"), ';')
+	if err == nil {
+		t.Fatal("want an error for a record with too few fields")
+	}
+}
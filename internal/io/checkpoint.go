@@ -0,0 +1,88 @@
+package io
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+)
+
+// Checkpoint is the state of a loan as reported by the bank on some
+// day, e.g. on a statement.
+type Checkpoint struct {
+	Day     time.Time
+	Balance *big.Rat
+	// Interest is the reported accrued interest, or nil if the bank
+	// did not report it.
+	Interest *big.Rat
+}
+
+// ReadCheckpoints reads checkpoints from a CSV file with a header
+// line followed by records of date, reported balance, and reported
+// accrued interest. Amounts are parsed with [ParseAmount]. The
+// interest may be left empty or set to "-" if unknown.
+func ReadCheckpoints(csvFilename string, comma rune) ([]Checkpoint, error) {
+	file, err := os.Open(csvFilename)
+	if err != nil {
+		return nil, fmt.Errorf("opening CSV file: %w", err)
+	}
+	defer file.Close()
+
+	checkpoints := []Checkpoint{}
+
+	r := csv.NewReader(file)
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+
+	if _, err := r.Read(); err != nil { // skip first line
+		return nil, fmt.Errorf("reading checkpoint CSV header: %w", err)
+	}
+
+	for {
+		r, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading checkpoint CSV record: %w", err)
+		}
+		if len(r) < 2 {
+			return nil, fmt.Errorf("checkpoint CSV record has %d field(s), want at least 2", len(r))
+		}
+
+		rDate, rBalance, rInterest := r[0], r[1], ""
+		if len(r) > 2 {
+			rInterest = r[2]
+		}
+
+		date, err := time.Parse(internal.DateLayout, rDate)
+		if err != nil {
+			return nil, fmt.Errorf("parsing date: %w", err)
+		}
+
+		balance, err := ParseAmount(rBalance)
+		if err != nil {
+			return nil, fmt.Errorf("parsing balance %q: %w", rBalance, err)
+		}
+
+		var interest *big.Rat
+		if rInterest != "" && rInterest != "-" {
+			interest, err = ParseAmount(rInterest)
+			if err != nil {
+				return nil, fmt.Errorf("parsing interest %q: %w", rInterest, err)
+			}
+		}
+
+		checkpoints = append(checkpoints, Checkpoint{
+			Day:      date,
+			Balance:  balance,
+			Interest: interest,
+		})
+	}
+
+	return checkpoints, nil
+}
@@ -0,0 +1,34 @@
+package io
+
+import (
+	"path"
+	"testing"
+	"time"
+)
+
+func TestReadCheckpoints(t *testing.T) {
+	checkpoints, err := ReadCheckpoints(path.Join("..", "testdata", "checkpoints.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading checkpoints: %s", err)
+	}
+
+	if want, got := 4, len(checkpoints); want != got {
+		t.Fatalf("want %d checkpoints, but got %d", want, got)
+	}
+
+	if want, got := time.Date(2022, 8, 10, 0, 0, 0, 0, time.UTC), checkpoints[0].Day; want != got {
+		t.Errorf("want day %v, but got %v", want, got)
+	}
+
+	if want, got := "92173.64", checkpoints[2].Balance.FloatString(2); want != got {
+		t.Errorf("want balance %s, but got %s", want, got)
+	}
+
+	if got := checkpoints[2].Interest; got != nil {
+		t.Errorf("want no interest, but got %s", got)
+	}
+
+	if want, got := "6.57", checkpoints[3].Interest.FloatString(2); want != got {
+		t.Errorf("want interest %s, but got %s", want, got)
+	}
+}
@@ -0,0 +1,121 @@
+package io
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+)
+
+// ScheduledFee is a fee charged on a loan by schedule, e.g. a setup fee,
+// a late fee, or a monthly notice fee (aviavgift).
+type ScheduledFee struct {
+	Description string
+	// Monthly is true if the fee is charged every month on the same
+	// day of month as Start, or on the last day of shorter months.
+	// Otherwise, the fee is charged once on Start.
+	Monthly bool
+	Start   time.Time
+	// End is the last day a monthly fee may be charged, or the zero
+	// time if it is charged indefinitely.
+	End    time.Time
+	Amount *big.Rat
+}
+
+// ChargedOn reports whether the fee is charged on day.
+func (f ScheduledFee) ChargedOn(day time.Time) bool {
+	if day.Before(f.Start) || (!f.End.IsZero() && day.After(f.End)) {
+		return false
+	}
+
+	if !f.Monthly {
+		return day.Equal(f.Start)
+	}
+
+	y, m, d := day.Date()
+	lastDay := time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+	feeDay := f.Start.Day()
+	if feeDay > lastDay {
+		feeDay = lastDay
+	}
+
+	return d == feeDay
+}
+
+// ReadFees reads a fee schedule from a CSV file with a header line
+// followed by records of description, "once" or "monthly", start date,
+// end date (may be empty), and amount.
+func ReadFees(csvFilename string, comma rune) ([]ScheduledFee, error) {
+	file, err := os.Open(csvFilename)
+	if err != nil {
+		return nil, fmt.Errorf("opening CSV file: %w", err)
+	}
+	defer file.Close()
+
+	fees := []ScheduledFee{}
+
+	r := csv.NewReader(file)
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+
+	if _, err := r.Read(); err != nil { // skip first line
+		return nil, fmt.Errorf("reading fee CSV header: %w", err)
+	}
+
+	for {
+		r, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading fee CSV record: %w", err)
+		}
+		if len(r) < 5 {
+			return nil, fmt.Errorf("fee CSV record has %d field(s), want at least 5", len(r))
+		}
+
+		rDesc, rType, rStart, rEnd, rAmount := r[0], r[1], r[2], r[3], r[4]
+
+		var monthly bool
+		switch strings.ToLower(rType) {
+		case "once":
+		case "monthly":
+			monthly = true
+		default:
+			return nil, fmt.Errorf("unknown fee type %q, want once or monthly", rType)
+		}
+
+		start, err := time.Parse(internal.DateLayout, rStart)
+		if err != nil {
+			return nil, fmt.Errorf("parsing start date: %w", err)
+		}
+
+		var end time.Time
+		if rEnd != "" {
+			end, err = time.Parse(internal.DateLayout, rEnd)
+			if err != nil {
+				return nil, fmt.Errorf("parsing end date: %w", err)
+			}
+		}
+
+		amount, err := ParseAmount(rAmount)
+		if err != nil {
+			return nil, fmt.Errorf("parsing amount %q: %w", rAmount, err)
+		}
+
+		fees = append(fees, ScheduledFee{
+			Description: rDesc,
+			Monthly:     monthly,
+			Start:       start,
+			End:         end,
+			Amount:      amount,
+		})
+	}
+
+	return fees, nil
+}
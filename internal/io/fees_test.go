@@ -0,0 +1,67 @@
+package io
+
+import (
+	"path"
+	"testing"
+	"time"
+)
+
+func TestReadFees(t *testing.T) {
+	fees, err := ReadFees(path.Join("..", "testdata", "fees.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading fees: %s", err)
+	}
+
+	if want, got := 2, len(fees); want != got {
+		t.Fatalf("want %d fees, but got %d", want, got)
+	}
+
+	if fees[0].Monthly || !fees[1].Monthly {
+		t.Errorf("want once and monthly fee, but got %+v", fees)
+	}
+
+	if want, got := "29.00", fees[1].Amount.FloatString(2); want != got {
+		t.Errorf("want amount %s, but got %s", want, got)
+	}
+
+	if !fees[1].End.IsZero() {
+		t.Errorf("want no end, but got %v", fees[1].End)
+	}
+}
+
+func TestFeeChargedOn(t *testing.T) {
+	monthly := ScheduledFee{
+		Monthly: true,
+		Start:   time.Date(2022, 1, 31, 0, 0, 0, 0, time.UTC),
+		End:     time.Date(2022, 5, 31, 0, 0, 0, 0, time.UTC),
+	}
+	once := ScheduledFee{Start: time.Date(2022, 3, 15, 0, 0, 0, 0, time.UTC)}
+
+	tests := []struct {
+		fee  ScheduledFee
+		day  time.Time
+		want bool
+	}{
+		{monthly, time.Date(2022, 1, 30, 0, 0, 0, 0, time.UTC), false},
+		{monthly, time.Date(2022, 1, 31, 0, 0, 0, 0, time.UTC), true},
+		{monthly, time.Date(2022, 2, 28, 0, 0, 0, 0, time.UTC), true},
+		{monthly, time.Date(2022, 4, 30, 0, 0, 0, 0, time.UTC), true},
+		{monthly, time.Date(2022, 5, 30, 0, 0, 0, 0, time.UTC), false},
+		{monthly, time.Date(2022, 6, 30, 0, 0, 0, 0, time.UTC), false},
+		{once, time.Date(2022, 3, 15, 0, 0, 0, 0, time.UTC), true},
+		{once, time.Date(2022, 4, 15, 0, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.fee.ChargedOn(tt.day); got != tt.want {
+			t.Errorf("%+v on %s: want %t, but got %t", tt.fee, tt.day, tt.want, got)
+		}
+	}
+}
+
+func TestReadFees_ShortRecord(t *testing.T) {
+	_, err := ReadFees(writeCSV(t, "Description;Type;Start;End\nAviavgift;monthly;2022-06-28;\n"), ';')
+	if err == nil {
+		t.Fatal("want an error for a record with too few fields")
+	}
+}
@@ -0,0 +1,97 @@
+package io
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+)
+
+// FixedRatePeriod is a period during which the annual interest rate of
+// a loan is fixed (bindningstid), regardless of the floating rate.
+type FixedRatePeriod struct {
+	// Start is the first day of the period.
+	Start time.Time
+	// End is the day the period ends (villkorsändringsdag), i.e. the
+	// first day when the floating rate applies again.
+	End         time.Time
+	DecimalRate *big.Rat
+}
+
+// Contains reports whether day is within the period.
+func (p FixedRatePeriod) Contains(day time.Time) bool {
+	return !day.Before(p.Start) && day.Before(p.End)
+}
+
+func (p FixedRatePeriod) String() string {
+	return fmt.Sprintf("%v–%v %s",
+		p.Start.Format(internal.DateLayout), p.End.Format(internal.DateLayout), p.DecimalRate)
+}
+
+// ReadFixedRatePeriods reads fixed-rate periods from a CSV file with a
+// header line followed by records of start date, end date, and annual
+// interest rate in percent.
+func ReadFixedRatePeriods(csvFilename string, comma rune) ([]FixedRatePeriod, error) {
+	file, err := os.Open(csvFilename)
+	if err != nil {
+		return nil, fmt.Errorf("opening CSV file: %w", err)
+	}
+	defer file.Close()
+
+	periods := []FixedRatePeriod{}
+	bigRat100 := big.NewRat(100, 1)
+
+	r := csv.NewReader(file)
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+
+	if _, err := r.Read(); err != nil { // skip first line
+		return nil, fmt.Errorf("reading fixed-rate period CSV header: %w", err)
+	}
+
+	for {
+		r, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading fixed-rate period CSV record: %w", err)
+		}
+		if len(r) < 3 {
+			return nil, fmt.Errorf("fixed-rate period CSV record has %d field(s), want at least 3", len(r))
+		}
+
+		rStart, rEnd, rPercentage := r[0], r[1], r[2]
+
+		start, err := time.Parse(internal.DateLayout, rStart)
+		if err != nil {
+			return nil, fmt.Errorf("parsing start date: %w", err)
+		}
+
+		end, err := time.Parse(internal.DateLayout, rEnd)
+		if err != nil {
+			return nil, fmt.Errorf("parsing end date: %w", err)
+		}
+
+		if !start.Before(end) {
+			return nil, fmt.Errorf("fixed-rate period starting %s does not end after it starts", rStart)
+		}
+
+		percentage, ok := new(big.Rat).SetString(rPercentage)
+		if !ok {
+			return nil, fmt.Errorf("setting big rat to %q", rPercentage)
+		}
+
+		periods = append(periods, FixedRatePeriod{
+			Start:       start,
+			End:         end,
+			DecimalRate: percentage.Quo(percentage, bigRat100),
+		})
+	}
+
+	return periods, nil
+}
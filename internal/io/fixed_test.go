@@ -0,0 +1,62 @@
+package io
+
+import (
+	"path"
+	"testing"
+	"time"
+)
+
+func TestReadFixedRatePeriods(t *testing.T) {
+	periods, err := ReadFixedRatePeriods(path.Join("..", "testdata", "fixed_rate_periods.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading fixed-rate periods: %s", err)
+	}
+
+	if want, got := 1, len(periods); want != got {
+		t.Fatalf("want %d periods, but got %d", want, got)
+	}
+
+	p := periods[0]
+
+	if want, got := time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC), p.Start; want != got {
+		t.Errorf("want start %v, but got %v", want, got)
+	}
+
+	if want, got := time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC), p.End; want != got {
+		t.Errorf("want end %v, but got %v", want, got)
+	}
+
+	if want, got := "0.0320", p.DecimalRate.FloatString(4); want != got {
+		t.Errorf("want rate %s, but got %s", want, got)
+	}
+}
+
+func TestFixedRatePeriodContains(t *testing.T) {
+	p := FixedRatePeriod{
+		Start: time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		day  time.Time
+		want bool
+	}{
+		{time.Date(2022, 9, 30, 0, 0, 0, 0, time.UTC), false},
+		{time.Date(2022, 10, 1, 0, 0, 0, 0, time.UTC), true},
+		{time.Date(2024, 9, 30, 0, 0, 0, 0, time.UTC), true},
+		{time.Date(2024, 10, 1, 0, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		if got := p.Contains(tt.day); got != tt.want {
+			t.Errorf("%s: want %t, but got %t", tt.day, tt.want, got)
+		}
+	}
+}
+
+func TestReadFixedRatePeriods_ShortRecord(t *testing.T) {
+	_, err := ReadFixedRatePeriods(writeCSV(t, "Start;End\n2022-01-01;2025-01-01\n"), ';')
+	if err == nil {
+		t.Fatal("want an error for a record with too few fields")
+	}
+}
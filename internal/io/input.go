@@ -22,8 +22,13 @@ type Transaction struct {
 	Description string    `csv:"Värdepapper/beskrivning"`
 	Amount      *big.Rat  `csv:"Belopp"`
 	Currency    string    `csv:"Valuta"`
+	// Kind is how the transaction affects the loan. It is derived
+	// from Type and Description.
+	Kind Kind `csv:"-"`
 }
 
+// ReadTransactions reads transactions from a CSV file exported by the
+// bank and classifies them with [DefaultKindRules].
 func ReadTransactions(csvFilename string, csvComma rune) ([]Transaction, error) {
 	file, err := os.Open(csvFilename)
 	if err != nil {
@@ -73,6 +78,8 @@ func ReadTransactions(csvFilename string, csvComma rune) ([]Transaction, error)
 		return nil, fmt.Errorf("reading all transactions: %w", err)
 	}
 
+	Classify(transactions, DefaultKindRules)
+
 	return transactions, nil
 }
 
@@ -0,0 +1,148 @@
+package io
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// Kind is the kind of a transaction, which determines how it affects
+// the loan.
+type Kind int
+
+const (
+	// Payment is a payment from the borrower that reduces the balance.
+	Payment Kind = iota
+	// Disbursement is money paid out to the borrower, e.g. a
+	// withdrawal (uttag) or a top-up of the loan, that increases the
+	// balance.
+	Disbursement
+	// Fee is a charge, other than interest, that increases the
+	// balance.
+	Fee
+	// InterestCharge is interest charged by the bank. It does not
+	// affect the loan, since interest is calculated separately.
+	InterestCharge
+	// Refund is money credited by the bank, e.g. returned interest,
+	// that reduces the balance without being a payment.
+	Refund
+)
+
+var kindNames = []string{"payment", "disbursement", "fee", "interest", "refund"}
+
+func (k Kind) String() string {
+	if k >= 0 && int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return fmt.Sprintf("Kind(%d)", int(k))
+}
+
+// ParseKind returns the kind named s, as returned by [Kind.String].
+func ParseKind(s string) (Kind, error) {
+	for k, name := range kindNames {
+		if strings.EqualFold(s, name) {
+			return Kind(k), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown transaction kind %q", s)
+}
+
+// KindRule maps transactions to a kind by their type and description.
+type KindRule struct {
+	// Type matches the type of transactions, ignoring case. An empty
+	// type matches all transactions.
+	Type string
+	// Description matches the description of transactions. A nil
+	// description matches all transactions.
+	Description *regexp.Regexp
+	Kind        Kind
+}
+
+// Match reports whether the rule matches t.
+func (r KindRule) Match(t Transaction) bool {
+	if r.Type != "" && !strings.EqualFold(r.Type, t.Type) {
+		return false
+	}
+	return r.Description == nil || r.Description.MatchString(t.Description)
+}
+
+// DefaultKindRules are the kind rules applied by [ReadTransactions].
+var DefaultKindRules = []KindRule{
+	{Description: regexp.MustCompile(`^ÅTERBETALN`), Kind: Refund},
+	{Type: "Insättning", Kind: Payment},
+	{Type: "Uttag", Kind: Disbursement},
+	{Type: "Avgift", Kind: Fee},
+	{Type: "Ränta", Kind: InterestCharge},
+}
+
+// Classify sets the kind of each transaction to that of the first
+// matching rule. Transactions that match no rule are payments.
+func Classify(transactions []Transaction, rules []KindRule) {
+	for i, t := range transactions {
+		transactions[i].Kind = Payment
+
+		for _, r := range rules {
+			if r.Match(t) {
+				transactions[i].Kind = r.Kind
+				break
+			}
+		}
+	}
+}
+
+// ReadKindRules reads kind rules from a CSV file with a header line
+// followed by records of transaction type, regular expression matching
+// the description, and kind. The type and description may be empty to
+// match all transactions.
+func ReadKindRules(csvFilename string, comma rune) ([]KindRule, error) {
+	file, err := os.Open(csvFilename)
+	if err != nil {
+		return nil, fmt.Errorf("opening CSV file: %w", err)
+	}
+	defer file.Close()
+
+	rules := []KindRule{}
+
+	r := csv.NewReader(file)
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+
+	if _, err := r.Read(); err != nil { // skip first line
+		return nil, fmt.Errorf("reading kind rule CSV header: %w", err)
+	}
+
+	for {
+		r, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading kind rule CSV record: %w", err)
+		}
+		if len(r) < 3 {
+			return nil, fmt.Errorf("kind rule CSV record has %d field(s), want at least 3", len(r))
+		}
+
+		rType, rDesc, rKind := r[0], r[1], r[2]
+
+		var desc *regexp.Regexp
+		if rDesc != "" {
+			desc, err = regexp.Compile(rDesc)
+			if err != nil {
+				return nil, fmt.Errorf("compiling description pattern: %w", err)
+			}
+		}
+
+		kind, err := ParseKind(rKind)
+		if err != nil {
+			return nil, err
+		}
+
+		rules = append(rules, KindRule{Type: rType, Description: desc, Kind: kind})
+	}
+
+	return rules, nil
+}
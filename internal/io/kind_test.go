@@ -0,0 +1,112 @@
+package io
+
+import (
+	"os"
+	"path"
+	"regexp"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	transactions := []Transaction{
+		{Type: "Insättning", Description: "RÄNTA+AMOR"},
+		{Type: "Insättning", Description: "ÅTERBETALN"},
+		{Type: "Uttag", Description: "UTBETALNING"},
+		{Type: "avgift", Description: "AVIAVGIFT"},
+		{Type: "Ränta", Description: "RÄNTA"},
+		{Type: "Okänd", Description: "OKÄND"},
+	}
+
+	Classify(transactions, DefaultKindRules)
+
+	want := []Kind{Payment, Refund, Disbursement, Fee, InterestCharge, Payment}
+
+	for i, tr := range transactions {
+		if tr.Kind != want[i] {
+			t.Errorf("%s %s: want kind %s, but got %s", tr.Type, tr.Description, want[i], tr.Kind)
+		}
+	}
+
+	rules := append([]KindRule{
+		{Description: regexp.MustCompile(`^OKÄND$`), Kind: Fee},
+	}, DefaultKindRules...)
+
+	Classify(transactions, rules)
+
+	if want, got := Fee, transactions[5].Kind; want != got {
+		t.Errorf("want kind %s, but got %s", want, got)
+	}
+}
+
+func TestReadTransactions_Kinds(t *testing.T) {
+	transactions, err := ReadTransactions(path.Join("..", "testdata", "transactions.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading transactions: %s", err)
+	}
+
+	want := []Kind{Refund, Payment, Payment, Payment}
+
+	for i, tr := range transactions {
+		if tr.Kind != want[i] {
+			t.Errorf("i=%d: want kind %s, but got %s", i, want[i], tr.Kind)
+		}
+	}
+}
+
+func TestReadKindRules(t *testing.T) {
+	rules, err := ReadKindRules(path.Join("..", "testdata", "kind_rules.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading kind rules: %s", err)
+	}
+
+	if want, got := 2, len(rules); want != got {
+		t.Fatalf("want %d rules, but got %d", want, got)
+	}
+
+	if !rules[0].Match(Transaction{Type: "Insättning", Description: "UTBETALNING LÅN"}) {
+		t.Error("want first rule to match")
+	}
+
+	if want, got := Disbursement, rules[0].Kind; want != got {
+		t.Errorf("want kind %s, but got %s", want, got)
+	}
+
+	if !rules[1].Match(Transaction{Type: "Övrigt", Description: "x"}) {
+		t.Error("want second rule to match")
+	}
+
+	if want, got := Fee, rules[1].Kind; want != got {
+		t.Errorf("want kind %s, but got %s", want, got)
+	}
+}
+
+// writeCSV writes content to a CSV file in a temporary directory and
+// returns its name.
+func writeCSV(t *testing.T, content string) string {
+	t.Helper()
+
+	name := path.Join(t.TempDir(), "input.csv")
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatalf("writing CSV file: %s", err)
+	}
+	return name
+}
+
+func TestReadKindRules_ShortRecord(t *testing.T) {
+	_, err := ReadKindRules(writeCSV(t, "Type;Description\nÖvrigt;x\n"), ';')
+	if err == nil {
+		t.Fatal("want an error for a record with too few fields")
+	}
+}
+
+func TestParseKind(t *testing.T) {
+	for _, k := range []Kind{Payment, Disbursement, Fee, InterestCharge, Refund} {
+		if got, err := ParseKind(k.String()); err != nil || got != k {
+			t.Errorf("want %s, but got %s (err=%v)", k, got, err)
+		}
+	}
+
+	if _, err := ParseKind("gift"); err == nil {
+		t.Error("want error, but got nil")
+	}
+}
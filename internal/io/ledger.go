@@ -0,0 +1,207 @@
+package io
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+)
+
+// LedgerEntry is a transaction stored in a ledger, with where and when
+// it was imported from.
+type LedgerEntry struct {
+	Transaction
+	// Source is the file that the transaction was imported from.
+	Source string
+	// Imported is when the transaction was imported.
+	Imported time.Time
+	// Classified is true if the kind of the transaction was given when
+	// it was added, e.g. through the HTTP API, so that kind rules do
+	// not change it.
+	Classified bool
+}
+
+// key identifies the transaction of e among the transactions of
+// overlapping statements.
+func (e LedgerEntry) key() string {
+	return transactionKey(e.Transaction)
+}
+
+func transactionKey(t Transaction) string {
+	return fmt.Sprintf("%s|%s|%s|%s|%s",
+		t.Date.Format(internal.DateLayout), t.Type, t.Description, t.Amount.RatString(), t.Currency)
+}
+
+// ledgerLine is the JSON representation of a LedgerEntry, one per line
+// of a ledger file. Amounts are exact fractions formatted as by
+// [big.Rat.RatString].
+type ledgerLine struct {
+	Date        string    `json:"date"`
+	Type        string    `json:"type"`
+	Description string    `json:"description"`
+	Amount      string    `json:"amount"`
+	Currency    string    `json:"currency"`
+	Kind        string    `json:"kind,omitempty"`
+	Source      string    `json:"source"`
+	Imported    time.Time `json:"imported"`
+}
+
+// ReadLedger reads the entries of a ledger file, which holds one JSON
+// object per line, in the order they were imported. Only the
+// transactions whose kind was given when they were added are
+// classified (see [LedgerTransactions]).
+func ReadLedger(jsonlFilename string) ([]LedgerEntry, error) {
+	file, err := os.Open(jsonlFilename)
+	if err != nil {
+		return nil, fmt.Errorf("opening ledger file: %w", err)
+	}
+	defer file.Close()
+
+	var entries []LedgerEntry
+
+	s := bufio.NewScanner(file)
+	for n := 1; s.Scan(); n++ {
+		if len(s.Bytes()) == 0 {
+			continue
+		}
+
+		var l ledgerLine
+		if err := json.Unmarshal(s.Bytes(), &l); err != nil {
+			return nil, fmt.Errorf("decoding ledger line %d: %w", n, err)
+		}
+
+		date, err := time.Parse(internal.DateLayout, l.Date)
+		if err != nil {
+			return nil, fmt.Errorf("parsing date on ledger line %d: %w", n, err)
+		}
+
+		amount, err := ParseAmount(l.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("parsing amount %q on ledger line %d: %w", l.Amount, n, err)
+		}
+
+		e := LedgerEntry{
+			Transaction: Transaction{
+				Date:        date,
+				Type:        l.Type,
+				Description: l.Description,
+				Amount:      amount,
+				Currency:    l.Currency,
+			},
+			Source:   l.Source,
+			Imported: l.Imported,
+		}
+		if l.Kind != "" {
+			e.Kind, err = ParseKind(l.Kind)
+			if err != nil {
+				return nil, fmt.Errorf("parsing kind on ledger line %d: %w", n, err)
+			}
+			e.Classified = true
+		}
+
+		entries = append(entries, e)
+	}
+	if err := s.Err(); err != nil {
+		return nil, fmt.Errorf("reading ledger file: %w", err)
+	}
+
+	return entries, nil
+}
+
+// LedgerTransactions returns the transactions of entries, classified
+// with rules unless their kind was given when they were added.
+func LedgerTransactions(entries []LedgerEntry, rules []KindRule) []Transaction {
+	transactions := make([]Transaction, len(entries))
+	for i, e := range entries {
+		transactions[i] = e.Transaction
+		if !e.Classified {
+			Classify(transactions[i:i+1], rules)
+		}
+	}
+
+	return transactions
+}
+
+// Import appends the transactions read from source that are not
+// already in the ledger file to it, creating the file if needed, and
+// returns the new entries. A transaction is already in the ledger if
+// one with the same date, type, description, amount, and currency is.
+// Identical transactions within source are told apart by count, so
+// that a statement with two identical payments on the same day adds
+// both of them, but only once.
+func Import(jsonlFilename, source string, transactions []Transaction, imported time.Time) ([]LedgerEntry, error) {
+	existing, err := ReadLedger(jsonlFilename)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+
+	counts := make(map[string]int)
+	for _, e := range existing {
+		counts[e.key()]++
+	}
+
+	var added []LedgerEntry
+	for _, t := range transactions {
+		k := transactionKey(t)
+		if counts[k] > 0 {
+			counts[k]--
+			continue
+		}
+		added = append(added, LedgerEntry{Transaction: t, Source: source, Imported: imported.UTC()})
+	}
+
+	if len(added) == 0 {
+		return nil, nil
+	}
+
+	if err := Append(jsonlFilename, added); err != nil {
+		return nil, err
+	}
+	return added, nil
+}
+
+// Append appends entries to the ledger file, creating the file if
+// needed. Unlike [Import], it adds transactions that are already in
+// the ledger, e.g. a second payment of the same amount on the same
+// day.
+func Append(jsonlFilename string, entries []LedgerEntry) error {
+	file, err := os.OpenFile(jsonlFilename, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("opening ledger file: %w", err)
+	}
+
+	w := bufio.NewWriter(file)
+	e := json.NewEncoder(w)
+	for _, a := range entries {
+		l := ledgerLine{
+			Date:        a.Date.Format(internal.DateLayout),
+			Type:        a.Type,
+			Description: a.Description,
+			Amount:      a.Amount.RatString(),
+			Currency:    a.Currency,
+			Source:      a.Source,
+			Imported:    a.Imported,
+		}
+		if a.Classified {
+			l.Kind = a.Kind.String()
+		}
+		if err := e.Encode(l); err != nil {
+			file.Close()
+			return fmt.Errorf("encoding ledger entry: %w", err)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		file.Close()
+		return fmt.Errorf("writing ledger file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("writing ledger file: %w", err)
+	}
+
+	return nil
+}
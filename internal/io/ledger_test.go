@@ -0,0 +1,122 @@
+package io
+
+import (
+	"path"
+	"testing"
+	"time"
+)
+
+func TestImport(t *testing.T) {
+	ledger := path.Join(t.TempDir(), "ledger.jsonl")
+	imported := time.Date(2023, 1, 5, 12, 0, 0, 0, time.UTC)
+
+	transactions, err := ReadTransactions(path.Join("..", "testdata", "transactions.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading transactions: %s", err)
+	}
+
+	added, err := Import(ledger, "transactions.csv", transactions, imported)
+	if err != nil {
+		t.Fatalf("importing transactions: %s", err)
+	}
+	if want, got := len(transactions), len(added); want != got {
+		t.Errorf("want %d added, but got %d", want, got)
+	}
+
+	// A later statement overlaps the first one, and has two identical
+	// payments on the same day, one of which is already in the ledger.
+	twice := transactions[0]
+	newer := MustNewTransaction(2022, 12, 27, "3000")
+	statement := append([]Transaction{newer, twice}, transactions...)
+
+	added, err = Import(ledger, "later.csv", statement, imported.AddDate(0, 1, 0))
+	if err != nil {
+		t.Fatalf("importing overlapping transactions: %s", err)
+	}
+	if want, got := 2, len(added); want != got {
+		t.Fatalf("want %d added, but got %d", want, got)
+	}
+
+	entries, err := ReadLedger(ledger)
+	if err != nil {
+		t.Fatalf("reading ledger: %s", err)
+	}
+	if want, got := len(transactions)+2, len(entries); want != got {
+		t.Fatalf("want %d entries, but got %d", want, got)
+	}
+
+	last := entries[len(entries)-1]
+	if want, got := "later.csv", last.Source; want != got {
+		t.Errorf("want source %q, but got %q", want, got)
+	}
+	if want, got := imported.AddDate(0, 1, 0), last.Imported; !want.Equal(got) {
+		t.Errorf("want import time %s, but got %s", want, got)
+	}
+
+	for i, tr := range LedgerTransactions(entries, DefaultKindRules)[:len(transactions)] {
+		if want, got := transactions[i].Amount, tr.Amount; want.Cmp(got) != 0 {
+			t.Errorf("entry %d: want amount %s, but got %s", i, want, got)
+		}
+		if want, got := transactions[i].Kind, tr.Kind; want != got {
+			t.Errorf("entry %d: want kind %s, but got %s", i, want, got)
+		}
+	}
+
+	// Importing the same statement again adds nothing.
+	added, err = Import(ledger, "later.csv", statement, imported.AddDate(0, 2, 0))
+	if err != nil {
+		t.Fatalf("importing the same transactions again: %s", err)
+	}
+	if want, got := 0, len(added); want != got {
+		t.Errorf("want %d added, but got %d", want, got)
+	}
+}
+
+func TestAppend(t *testing.T) {
+	ledger := path.Join(t.TempDir(), "ledger.jsonl")
+	imported := time.Date(2023, 1, 5, 12, 0, 0, 0, time.UTC)
+	payment := MustNewTransaction(2022, 12, 27, "3000")
+
+	for i := 0; i < 2; i++ {
+		if err := Append(ledger, []LedgerEntry{{Transaction: payment, Source: "api", Imported: imported}}); err != nil {
+			t.Fatalf("appending transaction: %s", err)
+		}
+	}
+
+	entries, err := ReadLedger(ledger)
+	if err != nil {
+		t.Fatalf("reading ledger: %s", err)
+	}
+	if want, got := 2, len(entries); want != got {
+		t.Errorf("want both identical transactions in the ledger, but got %d", got)
+	}
+}
+
+func TestAppend_Kind(t *testing.T) {
+	ledger := path.Join(t.TempDir(), "ledger.jsonl")
+
+	fee := MustNewTransaction(2022, 12, 27, "25")
+	fee.Type = "Insättning"
+	fee.Kind = Fee
+
+	err := Append(ledger, []LedgerEntry{
+		{Transaction: fee, Source: "api", Classified: true},
+		{Transaction: fee, Source: "api"},
+	})
+	if err != nil {
+		t.Fatalf("appending transactions: %s", err)
+	}
+
+	entries, err := ReadLedger(ledger)
+	if err != nil {
+		t.Fatalf("reading ledger: %s", err)
+	}
+
+	transactions := LedgerTransactions(entries, DefaultKindRules)
+	if want, got := Fee, transactions[0].Kind; want != got {
+		t.Errorf("want given kind %s, but got %s", want, got)
+	}
+	if want, got := Payment, transactions[1].Kind; want != got {
+		t.Errorf("want classified kind %s, but got %s", want, got)
+	}
+}
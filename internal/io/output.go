@@ -0,0 +1,40 @@
+package io
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+)
+
+// WriteInterestRates writes rates to w in the CSV format read by
+// [ReadInterestRates], with percentages given to four decimals.
+func WriteInterestRates(w io.Writer, rates []AnnualInterestRate, comma rune) error {
+	writer := csv.NewWriter(w)
+	writer.Comma = comma
+
+	if err := writer.Write([]string{"Date", "Percentage"}); err != nil {
+		return fmt.Errorf("writing interest rate CSV header: %w", err)
+	}
+
+	bigRat100 := big.NewRat(100, 1)
+
+	for _, r := range rates {
+		percentage := new(big.Rat).Mul(r.DecimalRate, bigRat100)
+		if err := writer.Write([]string{
+			r.Day.Format(internal.DateLayout),
+			percentage.FloatString(4),
+		}); err != nil {
+			return fmt.Errorf("writing interest rate CSV record: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return fmt.Errorf("flushing interest rate CSV: %w", err)
+	}
+
+	return nil
+}
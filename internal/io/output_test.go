@@ -0,0 +1,45 @@
+package io
+
+import (
+	"os"
+	"path"
+	"testing"
+	"time"
+)
+
+func TestWriteInterestRates(t *testing.T) {
+	rates := []AnnualInterestRate{
+		MustNewAnnualInterestRate(2022, time.January, 1, "0.0114"),
+		MustNewAnnualInterestRate(2022, time.July, 6, "0.016425"),
+	}
+
+	filename := path.Join(t.TempDir(), "rates.csv")
+
+	file, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteInterestRates(file, rates, ';'); err != nil {
+		t.Errorf("writing interest rates: %s", err)
+	}
+
+	if err := file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ReadInterestRates(filename, ';')
+	if err != nil {
+		t.Fatalf("reading interest rates: %s", err)
+	}
+
+	if want, got := len(rates), len(got); want != got {
+		t.Fatalf("length %d != %d", want, got)
+	}
+
+	for i := range rates {
+		if !rates[i].Equal(got[i]) {
+			t.Errorf("i=%d, want %s, but got %s", i, rates[i], got[i])
+		}
+	}
+}
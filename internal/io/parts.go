@@ -0,0 +1,97 @@
+package io
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"path/filepath"
+)
+
+// LoanPart describes one part (lånedel) of a loan split into several
+// parts.
+type LoanPart struct {
+	Name      string
+	Principal *big.Rat
+	// Share is the share, as a decimal, of each transaction allocated
+	// to the part.
+	Share *big.Rat
+	// InterestRates is the name of the part's interest rates CSV file.
+	InterestRates string
+	// FixedRatePeriods is the name of the part's fixed-rate periods
+	// CSV file, or empty if the part has none.
+	FixedRatePeriods string
+}
+
+// ReadLoanParts reads loan parts from a CSV file with a header line
+// followed by records of name, principal, share of transactions in
+// percent, interest rates file, and (optionally) fixed-rate periods
+// file. Relative file names are relative to the directory of the
+// loan parts file.
+func ReadLoanParts(csvFilename string, comma rune) ([]LoanPart, error) {
+	file, err := os.Open(csvFilename)
+	if err != nil {
+		return nil, fmt.Errorf("opening CSV file: %w", err)
+	}
+	defer file.Close()
+
+	parts := []LoanPart{}
+	dir := filepath.Dir(csvFilename)
+	bigRat100 := big.NewRat(100, 1)
+
+	r := csv.NewReader(file)
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+
+	if _, err := r.Read(); err != nil { // skip first line
+		return nil, fmt.Errorf("reading loan part CSV header: %w", err)
+	}
+
+	for {
+		r, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading loan part CSV record: %w", err)
+		}
+		if len(r) < 4 {
+			return nil, fmt.Errorf("loan part CSV record has %d field(s), want at least 4", len(r))
+		}
+
+		rName, rPrincipal, rShare, rRates, rFixed := r[0], r[1], r[2], r[3], ""
+		if len(r) > 4 {
+			rFixed = r[4]
+		}
+
+		principal, err := ParseAmount(rPrincipal)
+		if err != nil {
+			return nil, fmt.Errorf("parsing principal %q: %w", rPrincipal, err)
+		}
+
+		share, err := ParseAmount(rShare)
+		if err != nil {
+			return nil, fmt.Errorf("parsing share %q: %w", rShare, err)
+		}
+
+		parts = append(parts, LoanPart{
+			Name:             rName,
+			Principal:        principal,
+			Share:            share.Quo(share, bigRat100),
+			InterestRates:    relativeTo(dir, rRates),
+			FixedRatePeriods: relativeTo(dir, rFixed),
+		})
+	}
+
+	return parts, nil
+}
+
+// relativeTo returns name relative to dir unless name is empty or
+// absolute.
+func relativeTo(dir, name string) string {
+	if name == "" || filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(dir, name)
+}
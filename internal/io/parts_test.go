@@ -0,0 +1,44 @@
+package io
+
+import (
+	"path"
+	"testing"
+)
+
+func TestReadLoanParts(t *testing.T) {
+	parts, err := ReadLoanParts(path.Join("..", "testdata", "loan_parts.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading loan parts: %s", err)
+	}
+
+	if want, got := 2, len(parts); want != got {
+		t.Fatalf("want %d parts, but got %d", want, got)
+	}
+
+	tests := []struct {
+		part                            LoanPart
+		name, principal, share          string
+		interestRates, fixedRatePeriods string
+	}{
+		{parts[0], "Rörlig", "60000.00", "0.60", path.Join("..", "testdata", "annual_interest_rates.csv"), ""},
+		{parts[1], "Bunden", "40000.00", "0.40", path.Join("..", "testdata", "annual_interest_rates.csv"), path.Join("..", "testdata", "fixed_rate_periods.csv")},
+	}
+
+	for _, tt := range tests {
+		if tt.part.Name != tt.name {
+			t.Errorf("want name %q, but got %q", tt.name, tt.part.Name)
+		}
+		if got := tt.part.Principal.FloatString(2); got != tt.principal {
+			t.Errorf("%s: want principal %s, but got %s", tt.name, tt.principal, got)
+		}
+		if got := tt.part.Share.FloatString(2); got != tt.share {
+			t.Errorf("%s: want share %s, but got %s", tt.name, tt.share, got)
+		}
+		if got := tt.part.InterestRates; got != tt.interestRates {
+			t.Errorf("%s: want interest rates %q, but got %q", tt.name, tt.interestRates, got)
+		}
+		if got := tt.part.FixedRatePeriods; got != tt.fixedRatePeriods {
+			t.Errorf("%s: want fixed-rate periods %q, but got %q", tt.name, tt.fixedRatePeriods, got)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package io
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+)
+
+// PlannedPayment is an entry of a payment plan. From its first day on,
+// until the next entry, Amount is due every month on day Day, or on the
+// last day of shorter months.
+type PlannedPayment struct {
+	From   time.Time
+	Day    int
+	Amount *big.Rat
+}
+
+// DueOn reports whether a payment is due on day according to the
+// entry, disregarding From.
+func (p PlannedPayment) DueOn(day time.Time) bool {
+	y, m, d := day.Date()
+	lastDay := time.Date(y, m+1, 0, 0, 0, 0, 0, time.UTC).Day()
+
+	dueDay := p.Day
+	if dueDay > lastDay {
+		dueDay = lastDay
+	}
+
+	return d == dueDay
+}
+
+// PlanOn returns the entry of plan, which must be sorted by From, in
+// effect on day.
+func PlanOn(plan []PlannedPayment, day time.Time) (entry PlannedPayment, ok bool) {
+	for _, p := range plan {
+		if p.From.After(day) {
+			break
+		}
+		entry, ok = p, true
+	}
+	return entry, ok
+}
+
+// SortPlan sorts plan by From.
+func SortPlan(plan []PlannedPayment) {
+	sort.SliceStable(plan, func(i, j int) bool {
+		return plan[i].From.Before(plan[j].From)
+	})
+}
+
+// ReadPaymentPlan reads a payment plan from a CSV file with a header
+// line followed by records of the first day of the entry, day of
+// month, and amount. The plan is returned sorted by first day.
+func ReadPaymentPlan(csvFilename string, comma rune) ([]PlannedPayment, error) {
+	file, err := os.Open(csvFilename)
+	if err != nil {
+		return nil, fmt.Errorf("opening CSV file: %w", err)
+	}
+	defer file.Close()
+
+	plan := []PlannedPayment{}
+
+	r := csv.NewReader(file)
+	r.Comma = comma
+	r.FieldsPerRecord = -1
+
+	if _, err := r.Read(); err != nil { // skip first line
+		return nil, fmt.Errorf("reading payment plan CSV header: %w", err)
+	}
+
+	for {
+		r, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading payment plan CSV record: %w", err)
+		}
+		if len(r) < 3 {
+			return nil, fmt.Errorf("payment plan CSV record has %d field(s), want at least 3", len(r))
+		}
+
+		rFrom, rDay, rAmount := r[0], r[1], r[2]
+
+		from, err := time.Parse(internal.DateLayout, rFrom)
+		if err != nil {
+			return nil, fmt.Errorf("parsing date: %w", err)
+		}
+
+		day, err := strconv.Atoi(rDay)
+		if err != nil || day < 1 || day > 31 {
+			return nil, fmt.Errorf("parsing day of month %q: want 1-31", rDay)
+		}
+
+		amount, err := ParseAmount(rAmount)
+		if err != nil {
+			return nil, fmt.Errorf("parsing amount %q: %w", rAmount, err)
+		}
+
+		plan = append(plan, PlannedPayment{From: from, Day: day, Amount: amount})
+	}
+
+	SortPlan(plan)
+
+	return plan, nil
+}
@@ -0,0 +1,66 @@
+package io
+
+import (
+	"path"
+	"testing"
+	"time"
+)
+
+func TestReadPaymentPlan(t *testing.T) {
+	plan, err := ReadPaymentPlan(path.Join("..", "testdata", "payment_plan.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading payment plan: %s", err)
+	}
+
+	if want, got := 2, len(plan); want != got {
+		t.Fatalf("want %d entries, but got %d", want, got)
+	}
+
+	if want, got := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), plan[0].From; want != got {
+		t.Errorf("want first entry from %v, but got %v", want, got)
+	}
+
+	if want, got := "2500.00", plan[1].Amount.FloatString(2); want != got {
+		t.Errorf("want amount %s, but got %s", want, got)
+	}
+}
+
+func TestPlanOn(t *testing.T) {
+	plan := []PlannedPayment{
+		{From: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Day: 27},
+		{From: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Day: 31},
+	}
+
+	if _, ok := PlanOn(plan, time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC)); ok {
+		t.Error("want no entry before the plan")
+	}
+
+	tests := []struct {
+		day     time.Time
+		wantDay int
+		wantDue bool
+	}{
+		{time.Date(2023, 1, 27, 0, 0, 0, 0, time.UTC), 27, true},
+		{time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC), 27, false},
+		{time.Date(2025, 2, 28, 0, 0, 0, 0, time.UTC), 31, true},
+		{time.Date(2025, 3, 30, 0, 0, 0, 0, time.UTC), 31, false},
+	}
+
+	for _, tt := range tests {
+		entry, ok := PlanOn(plan, tt.day)
+		if !ok || entry.Day != tt.wantDay {
+			t.Errorf("%s: want entry with day %d, but got %+v (ok=%t)", tt.day, tt.wantDay, entry, ok)
+		}
+
+		if got := entry.DueOn(tt.day); got != tt.wantDue {
+			t.Errorf("%s: want due %t, but got %t", tt.day, tt.wantDue, got)
+		}
+	}
+}
+
+func TestReadPaymentPlan_ShortRecord(t *testing.T) {
+	_, err := ReadPaymentPlan(writeCSV(t, "From;Day\n2022-01-01;27\n"), ';')
+	if err == nil {
+		t.Fatal("want an error for a record with too few fields")
+	}
+}
@@ -0,0 +1,251 @@
+package io
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sort"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+)
+
+// extraPaymentType is the type of the transactions made by the extra
+// payments of a scenario.
+const extraPaymentType = "Extra betalning"
+
+// Scenario is a set of hypothetical changes to the inputs of a loan,
+// overlaid on the actual transactions, interest rates, and payment
+// plan.
+type Scenario struct {
+	Name          string
+	ExtraPayments []Transaction
+	RateShocks    []RateShock
+	// Plan holds changes to the payment plan. Each entry replaces the
+	// plan from its first day on.
+	Plan []PlannedPayment
+}
+
+// RateShock is a change of the interest rate by a number of
+// percentage points (in decimal form) from a day on, until an
+// optional end day (exclusive).
+type RateShock struct {
+	From  time.Time
+	Until time.Time // zero if open-ended
+	Delta *big.Rat
+}
+
+// activeOn reports whether the shock applies on day.
+func (s RateShock) activeOn(day time.Time) bool {
+	return !day.Before(s.From) && (s.Until.IsZero() || day.Before(s.Until))
+}
+
+// scenarioFile is the JSON representation of a Scenario. Dates are
+// formatted as YYYY-MM-DD and amounts as strings as accepted by
+// ParseAmount.
+type scenarioFile struct {
+	Name          string `json:"name"`
+	ExtraPayments []struct {
+		Date        string `json:"date"`
+		Amount      string `json:"amount"`
+		Description string `json:"description"`
+	} `json:"extraPayments"`
+	RateShocks []struct {
+		From             string `json:"from"`
+		Until            string `json:"until"`
+		PercentagePoints string `json:"percentagePoints"`
+	} `json:"rateShocks"`
+	Plan []struct {
+		From   string `json:"from"`
+		Day    int    `json:"day"`
+		Amount string `json:"amount"`
+	} `json:"plan"`
+}
+
+// ReadScenario reads a scenario from a JSON file such as:
+//
+//	{
+//	  "name": "Bonus and higher rates",
+//	  "extraPayments": [{"date": "2025-03-25", "amount": "20000"}],
+//	  "rateShocks": [{"from": "2025-01-01", "percentagePoints": "2"}],
+//	  "plan": [{"from": "2025-01-01", "day": 27, "amount": "3000"}]
+//	}
+func ReadScenario(jsonFilename string) (Scenario, error) {
+	file, err := os.Open(jsonFilename)
+	if err != nil {
+		return Scenario{}, fmt.Errorf("opening JSON file: %w", err)
+	}
+	defer file.Close()
+
+	var f scenarioFile
+
+	d := json.NewDecoder(file)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&f); err != nil {
+		return Scenario{}, fmt.Errorf("decoding scenario JSON: %w", err)
+	}
+
+	s := Scenario{Name: f.Name}
+
+	for _, p := range f.ExtraPayments {
+		date, err := time.Parse(internal.DateLayout, p.Date)
+		if err != nil {
+			return Scenario{}, fmt.Errorf("parsing extra payment date: %w", err)
+		}
+
+		amount, err := ParseAmount(p.Amount)
+		if err != nil {
+			return Scenario{}, fmt.Errorf("parsing extra payment amount %q: %w", p.Amount, err)
+		}
+
+		description := p.Description
+		if description == "" {
+			description = extraPaymentType
+		}
+
+		s.ExtraPayments = append(s.ExtraPayments, Transaction{
+			Date:        date,
+			Type:        extraPaymentType,
+			Description: description,
+			Amount:      amount,
+			Currency:    "SEK",
+			Kind:        Payment,
+		})
+	}
+
+	for _, r := range f.RateShocks {
+		var shock RateShock
+
+		shock.From, err = time.Parse(internal.DateLayout, r.From)
+		if err != nil {
+			return Scenario{}, fmt.Errorf("parsing rate shock start: %w", err)
+		}
+
+		if r.Until != "" {
+			shock.Until, err = time.Parse(internal.DateLayout, r.Until)
+			if err != nil {
+				return Scenario{}, fmt.Errorf("parsing rate shock end: %w", err)
+			}
+		}
+
+		shock.Delta, err = ParseAmount(r.PercentagePoints)
+		if err != nil {
+			return Scenario{}, fmt.Errorf("parsing rate shock %q: %w", r.PercentagePoints, err)
+		}
+		shock.Delta.Quo(shock.Delta, big.NewRat(100, 1))
+
+		s.RateShocks = append(s.RateShocks, shock)
+	}
+
+	for _, p := range f.Plan {
+		from, err := time.Parse(internal.DateLayout, p.From)
+		if err != nil {
+			return Scenario{}, fmt.Errorf("parsing plan date: %w", err)
+		}
+
+		if p.Day < 1 || p.Day > 31 {
+			return Scenario{}, fmt.Errorf("parsing plan day of month %d: want 1-31", p.Day)
+		}
+
+		amount, err := ParseAmount(p.Amount)
+		if err != nil {
+			return Scenario{}, fmt.Errorf("parsing plan amount %q: %w", p.Amount, err)
+		}
+
+		s.Plan = append(s.Plan, PlannedPayment{From: from, Day: p.Day, Amount: amount})
+	}
+
+	return s, nil
+}
+
+// ApplyTransactions returns a copy of transactions with the extra
+// payments of s added.
+func (s Scenario) ApplyTransactions(transactions []Transaction) []Transaction {
+	applied := make([]Transaction, 0, len(transactions)+len(s.ExtraPayments))
+	applied = append(applied, transactions...)
+	return append(applied, s.ExtraPayments...)
+}
+
+// ApplyInterestRates returns interest rates with the rate shocks of s
+// added to rates. A rate is added on each day a shock starts or ends,
+// and days before the first of rates are left uncovered.
+func (s Scenario) ApplyInterestRates(rates []AnnualInterestRate) []AnnualInterestRate {
+	if len(s.RateShocks) == 0 {
+		return append([]AnnualInterestRate(nil), rates...)
+	}
+
+	sorted := append([]AnnualInterestRate(nil), rates...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Day.Before(sorted[j].Day)
+	})
+
+	days := make([]time.Time, 0, len(sorted)+2*len(s.RateShocks))
+	for _, r := range sorted {
+		days = append(days, r.Day)
+	}
+	for _, shock := range s.RateShocks {
+		days = append(days, shock.From)
+		if !shock.Until.IsZero() {
+			days = append(days, shock.Until)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Before(days[j])
+	})
+
+	var applied []AnnualInterestRate
+
+	for i, day := range days {
+		if i > 0 && day.Equal(days[i-1]) {
+			continue
+		}
+
+		var rate *big.Rat
+		for _, r := range sorted {
+			if r.Day.After(day) {
+				break
+			}
+			rate = r.DecimalRate
+		}
+		if rate == nil {
+			continue
+		}
+
+		shocked := new(big.Rat).Set(rate)
+		for _, shock := range s.RateShocks {
+			if shock.activeOn(day) {
+				shocked.Add(shocked, shock.Delta)
+			}
+		}
+
+		applied = append(applied, AnnualInterestRate{Day: day, DecimalRate: shocked})
+	}
+
+	return applied
+}
+
+// ApplyPlan returns plan changed by the plan of s, sorted by first
+// day. An entry of s replaces the entries of plan from its first day
+// on.
+func (s Scenario) ApplyPlan(plan []PlannedPayment) []PlannedPayment {
+	var applied []PlannedPayment
+
+	for _, p := range plan {
+		replaced := false
+		for _, q := range s.Plan {
+			if !p.From.Before(q.From) {
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			applied = append(applied, p)
+		}
+	}
+
+	applied = append(applied, s.Plan...)
+	SortPlan(applied)
+
+	return applied
+}
@@ -0,0 +1,94 @@
+package io
+
+import (
+	"math/big"
+	"path"
+	"testing"
+	"time"
+)
+
+func TestReadScenario(t *testing.T) {
+	s, err := ReadScenario(path.Join("..", "testdata", "scenario.json"))
+	if err != nil {
+		t.Fatalf("reading scenario: %s", err)
+	}
+
+	if want, got := "Bonus and higher rates", s.Name; want != got {
+		t.Errorf("want name %q, but got %q", want, got)
+	}
+
+	if want, got := 1, len(s.ExtraPayments); want != got {
+		t.Fatalf("want %d extra payments, but got %d", want, got)
+	}
+	if want, got := "20000.00", s.ExtraPayments[0].Amount.FloatString(2); want != got {
+		t.Errorf("want extra payment %s, but got %s", want, got)
+	}
+
+	if want, got := 2, len(s.RateShocks); want != got {
+		t.Fatalf("want %d rate shocks, but got %d", want, got)
+	}
+	if want, got := "0.0050", s.RateShocks[1].Delta.FloatString(4); want != got {
+		t.Errorf("want rate shock %s, but got %s", want, got)
+	}
+}
+
+func TestScenarioApplyInterestRates(t *testing.T) {
+	s := Scenario{RateShocks: []RateShock{
+		{From: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Delta: mustParseAmount("0.02")},
+		{
+			From:  time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC),
+			Until: time.Date(2023, 9, 1, 0, 0, 0, 0, time.UTC),
+			Delta: mustParseAmount("0.005"),
+		},
+	}}
+
+	got := s.ApplyInterestRates([]AnnualInterestRate{
+		MustNewAnnualInterestRate(2022, 7, 1, "0.03"),
+		MustNewAnnualInterestRate(2022, 1, 1, "0.01"),
+		MustNewAnnualInterestRate(2023, 7, 1, "0.04"),
+	})
+
+	want := []AnnualInterestRate{
+		MustNewAnnualInterestRate(2022, 1, 1, "0.01"),
+		MustNewAnnualInterestRate(2022, 7, 1, "0.03"),
+		MustNewAnnualInterestRate(2023, 1, 1, "0.05"),
+		MustNewAnnualInterestRate(2023, 6, 1, "0.055"),
+		MustNewAnnualInterestRate(2023, 7, 1, "0.065"),
+		MustNewAnnualInterestRate(2023, 9, 1, "0.06"),
+	}
+
+	if len(want) != len(got) {
+		t.Fatalf("want %v, but got %v", want, got)
+	}
+	for i := range want {
+		if !want[i].Equal(got[i]) {
+			t.Errorf("want rate %s, but got %s", want[i], got[i])
+		}
+	}
+}
+
+func TestScenarioApplyPlan(t *testing.T) {
+	s := Scenario{Plan: []PlannedPayment{
+		{From: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Day: 25, Amount: mustParseAmount("3000")},
+	}}
+
+	got := s.ApplyPlan([]PlannedPayment{
+		{From: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), Day: 27, Amount: mustParseAmount("2500")},
+		{From: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), Day: 27, Amount: mustParseAmount("2000")},
+	})
+
+	if want, got := 2, len(got); want != got {
+		t.Fatalf("want %d entries, but got %d", want, got)
+	}
+	if want, got := 25, got[1].Day; want != got {
+		t.Errorf("want the scenario to replace the plan from 2024, but got day %d", got)
+	}
+}
+
+func mustParseAmount(amount string) *big.Rat {
+	a, err := ParseAmount(amount)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/calc"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/input"
+)
+
+// LoanConfig is the configuration of a loan served by a [Server]: its
+// inputs, as given to the commands that calculate a loan. The files are
+// read on every request, so changes to them take effect immediately.
+type LoanConfig struct {
+	ID     string
+	Inputs input.Flags
+}
+
+// configFile is the JSON representation of a list of LoanConfig.
+type configFile struct {
+	Loans []json.RawMessage `json:"loans"`
+}
+
+// ReadConfig reads the configuration of the loans to serve from a JSON
+// file, e.g.
+//
+//	{
+//	  "loans": [{
+//	    "id": "home",
+//	    "firstDay": "2022-06-07",
+//	    "principal": "100000",
+//	    "ledger": "ledger.jsonl",
+//	    "interestRates": "annual_interest_rates.csv"
+//	  }]
+//	}
+//
+// The keys of a loan are those of the JSON representation of
+// [input.Flags], and take the same default values, except that the
+// first day, principal, interest rates, and transactions or ledger
+// must be given. File names are relative to the directory of the
+// configuration file. The inputs of each loan are loaded once to check
+// them.
+func ReadConfig(jsonFilename string) ([]LoanConfig, error) {
+	file, err := os.Open(jsonFilename)
+	if err != nil {
+		return nil, fmt.Errorf("opening JSON file: %w", err)
+	}
+	defer file.Close()
+
+	var f configFile
+
+	if err := json.NewDecoder(file).Decode(&f); err != nil {
+		return nil, fmt.Errorf("decoding configuration JSON: %w", err)
+	}
+
+	var loans []LoanConfig
+	seen := make(map[string]bool)
+
+	for i, raw := range f.Loans {
+		l := struct {
+			ID string `json:"id"`
+			input.Flags
+		}{Flags: input.DefaultFlags()}
+		l.Transactions, l.InterestRates, l.FirstDay, l.Principal = "", "", "", ""
+
+		d := json.NewDecoder(bytes.NewReader(raw))
+		d.DisallowUnknownFields()
+		if err := d.Decode(&l); err != nil {
+			return nil, fmt.Errorf("decoding loan %d: %w", i+1, err)
+		}
+
+		if l.ID == "" {
+			return nil, fmt.Errorf("loan without id")
+		}
+		if seen[l.ID] {
+			return nil, fmt.Errorf("duplicate loan id %q", l.ID)
+		}
+		seen[l.ID] = true
+
+		if l.Transactions == "" && l.Ledger == "" {
+			return nil, fmt.Errorf("loan %q has neither transactions nor a ledger", l.ID)
+		}
+		if l.InterestRates == "" && l.ReferenceRates == "" {
+			return nil, fmt.Errorf("loan %q has no interest rates", l.ID)
+		}
+
+		l.Resolve(filepath.Dir(jsonFilename))
+		c := LoanConfig{ID: l.ID, Inputs: l.Flags}
+
+		if _, _, err := c.load(); err != nil {
+			return nil, fmt.Errorf("loading loan %q: %w", l.ID, err)
+		}
+
+		loans = append(loans, c)
+	}
+
+	return loans, nil
+}
+
+// load reads the inputs of the loan and returns them, and a bank
+// holding them.
+func (c LoanConfig) load() (input.Inputs, calc.Bank, error) {
+	in, err := c.Inputs.Load()
+	if err != nil {
+		return in, calc.Bank{}, err
+	}
+	return in, in.NewBank(), nil
+}
@@ -0,0 +1,396 @@
+// Package server serves the state of loans as JSON over HTTP.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/calc"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/input"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// apiSource is the source recorded in the ledger for transactions
+// posted to the server.
+const apiSource = "api"
+
+const (
+	// maxBodySize is the maximum size in bytes of a request body.
+	maxBodySize = 1 << 20
+	// maxYears is the maximum number of years after the first day of a
+	// loan that a date may be, to bound the days calculated for a
+	// request.
+	maxYears = 100
+)
+
+// Server is an http.Handler serving the loans of its configuration:
+//
+//	GET  /loans                        ids of the loans
+//	GET  /loans/{id}/balance?date=     state at the end of a day (default today)
+//	GET  /loans/{id}/schedule?from=&to= state at the end of each day (default first day through today)
+//	GET  /loans/{id}/summary/yearly    payments, interest, fees, and cost per year through today
+//	POST /loans/{id}/transactions      add a transaction to the ledger of the loan
+//
+// A posted transaction is always added, even if an identical one is
+// already in the ledger (see [io.Append]).
+//
+// Errors are returned as a JSON object with an "error" message.
+type Server struct {
+	loans map[string]LoanConfig
+	// now returns the current time. It is replaced in tests.
+	now func() time.Time
+	// mu serializes writes to the ledgers.
+	mu sync.Mutex
+}
+
+// New returns a server for loans.
+func New(loans []LoanConfig) *Server {
+	s := &Server{loans: make(map[string]LoanConfig), now: time.Now}
+	for _, l := range loans {
+		s.loans[l.ID] = l
+	}
+	return s
+}
+
+// httpError is an error with the HTTP status code to respond with.
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e *httpError) Error() string {
+	return e.err.Error()
+}
+
+func errorf(status int, format string, a ...interface{}) error {
+	return &httpError{status: status, err: fmt.Errorf(format, a...)}
+}
+
+// ServeHTTP routes the request by its method and path.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+
+	v, err := s.route(r)
+	if err != nil {
+		status := http.StatusInternalServerError
+
+		var he *httpError
+		var notFound *calc.RateNotFoundError
+		switch {
+		case errors.As(err, &he):
+			status = he.status
+		case errors.As(err, &notFound):
+			status = http.StatusUnprocessableEntity
+		}
+
+		writeJSON(w, status, map[string]string{"error": err.Error()})
+		return
+	}
+
+	status := http.StatusOK
+	if c, ok := v.(created); ok {
+		status, v = http.StatusCreated, c.v
+	}
+	writeJSON(w, status, v)
+}
+
+// created wraps a response to a request that created something.
+type created struct {
+	v interface{}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// route returns the response to r, to be encoded as JSON.
+func (s *Server) route(r *http.Request) (interface{}, error) {
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if parts[0] != "loans" {
+		return nil, errorf(http.StatusNotFound, "not found: %s", r.URL.Path)
+	}
+
+	if len(parts) == 1 {
+		if err := checkMethod(r, http.MethodGet); err != nil {
+			return nil, err
+		}
+		return s.ids(), nil
+	}
+
+	loan, ok := s.loans[parts[1]]
+	if !ok {
+		return nil, errorf(http.StatusNotFound, "no loan %q", parts[1])
+	}
+
+	switch strings.Join(parts[2:], "/") {
+	case "balance":
+		if err := checkMethod(r, http.MethodGet); err != nil {
+			return nil, err
+		}
+		return s.balance(r, loan)
+	case "schedule":
+		if err := checkMethod(r, http.MethodGet); err != nil {
+			return nil, err
+		}
+		return s.schedule(r, loan)
+	case "summary/yearly":
+		if err := checkMethod(r, http.MethodGet); err != nil {
+			return nil, err
+		}
+		return s.yearly(loan)
+	case "transactions":
+		if err := checkMethod(r, http.MethodPost); err != nil {
+			return nil, err
+		}
+		return s.addTransaction(r, loan)
+	}
+
+	return nil, errorf(http.StatusNotFound, "not found: %s", r.URL.Path)
+}
+
+func checkMethod(r *http.Request, method string) error {
+	if r.Method != method {
+		return errorf(http.StatusMethodNotAllowed, "method %s not allowed, want %s", r.Method, method)
+	}
+	return nil
+}
+
+func (s *Server) ids() []string {
+	ids := make([]string, 0, len(s.loans))
+	for id := range s.loans {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// dayState is the JSON representation of the state of a loan at the
+// end of a day. Amounts are rounded to two decimals.
+type dayState struct {
+	Date               string `json:"date"`
+	AnnualInterestRate string `json:"annualInterestRate,omitempty"`
+	Balance            string `json:"balance"`
+	AccruedInterest    string `json:"accruedInterest"`
+	UnpaidFees         string `json:"unpaidFees"`
+	Surplus            string `json:"surplus"`
+	Owed               string `json:"owed"`
+	PaidOff            bool   `json:"paidOff,omitempty"`
+}
+
+func newDayState(d calc.Day) dayState {
+	state := dayState{
+		Date:            d.Date.Format(internal.DateLayout),
+		Balance:         d.Loan.Balance().FloatString(2),
+		AccruedInterest: d.Loan.Interest().FloatString(2),
+		UnpaidFees:      d.Loan.Fees().FloatString(2),
+		Surplus:         d.Loan.Surplus().FloatString(2),
+		Owed:            d.Loan.Owed().FloatString(2),
+		PaidOff:         d.PaidOff,
+	}
+	if d.Rate != nil {
+		state.AnnualInterestRate = new(big.Rat).Mul(d.Rate, big.NewRat(100, 1)).FloatString(2)
+	}
+	return state
+}
+
+// dateParam returns the date of query parameter name of r, or def if
+// it is not given.
+func dateParam(r *http.Request, name string, def time.Time) (time.Time, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return calc.DateFromTime(def), nil
+	}
+
+	t, err := time.Parse(internal.DateLayout, v)
+	if err != nil {
+		return time.Time{}, errorf(http.StatusBadRequest, "parsing %s: %s", name, err)
+	}
+	return t, nil
+}
+
+// checkDate checks that date is within the days of the loan that are
+// calculated for a request.
+func checkDate(in input.Inputs, date time.Time) error {
+	if date.Before(in.FirstDay) {
+		return errorf(http.StatusBadRequest, "date %s is before the first day of the loan", date.Format(internal.DateLayout))
+	}
+	if date.After(in.FirstDay.AddDate(maxYears, 0, 0)) {
+		return errorf(http.StatusBadRequest, "date %s is more than %d years after the first day of the loan", date.Format(internal.DateLayout), maxYears)
+	}
+	return nil
+}
+
+func (s *Server) balance(r *http.Request, loan LoanConfig) (interface{}, error) {
+	date, err := dateParam(r, "date", s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	in, bank, err := loan.load()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkDate(in, date); err != nil {
+		return nil, err
+	}
+
+	days, err := calc.SeriesAt(&bank, calc.NewLoan(in.Principal), in.FirstDay, date, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return newDayState(days[len(days)-1]), nil
+}
+
+func (s *Server) schedule(r *http.Request, loan LoanConfig) (interface{}, error) {
+	in, bank, err := loan.load()
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := dateParam(r, "from", in.FirstDay)
+	if err != nil {
+		return nil, err
+	}
+	to, err := dateParam(r, "to", s.now())
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDate(in, to); err != nil {
+		return nil, err
+	}
+
+	states := []dayState{}
+	err = calc.Walk(r.Context(), &bank, calc.NewLoan(in.Principal), in.FirstDay, to, func(d calc.Day) error {
+		if !d.Date.Before(from) {
+			states = append(states, newDayState(d))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return states, nil
+}
+
+// yearSummary is the JSON representation of a calc.Summary of a year.
+type yearSummary struct {
+	Year            int    `json:"year"`
+	From            string `json:"from"`
+	To              string `json:"to"`
+	Payments        string `json:"payments"`
+	Interest        string `json:"interest"`
+	Fees            string `json:"fees"`
+	PenaltyInterest string `json:"penaltyInterest"`
+	Cost            string `json:"cost"`
+}
+
+func (s *Server) yearly(loan LoanConfig) (interface{}, error) {
+	in, bank, err := loan.load()
+	if err != nil {
+		return nil, err
+	}
+
+	days, err := calc.Series(&bank, calc.NewLoan(in.Principal), in.FirstDay, s.now())
+	if err != nil {
+		return nil, err
+	}
+
+	years := []yearSummary{}
+	for _, y := range calc.Yearly(&bank, days) {
+		years = append(years, yearSummary{
+			Year:            y.From.Year(),
+			From:            y.From.Format(internal.DateLayout),
+			To:              y.To.Format(internal.DateLayout),
+			Payments:        y.Payments.FloatString(2),
+			Interest:        y.Interest.FloatString(2),
+			Fees:            y.Fees.FloatString(2),
+			PenaltyInterest: y.PenaltyInterest.FloatString(2),
+			Cost:            y.Cost().FloatString(2),
+		})
+	}
+
+	return years, nil
+}
+
+// postedTransaction is the JSON representation of a transaction posted
+// to the server. Type defaults to a deposit (Insättning), i.e. a
+// payment, and Currency to SEK. Kind, e.g. "fee", is kept in the
+// ledger and overrides the kind rules of the loan, which otherwise
+// classify the transaction like any other.
+type postedTransaction struct {
+	Date        string `json:"date"`
+	Amount      string `json:"amount"`
+	Type        string `json:"type"`
+	Description string `json:"description"`
+	Currency    string `json:"currency"`
+	Kind        string `json:"kind"`
+}
+
+func (s *Server) addTransaction(r *http.Request, loan LoanConfig) (interface{}, error) {
+	if loan.Inputs.Ledger == "" {
+		return nil, errorf(http.StatusConflict, "loan %q has no ledger to add transactions to", loan.ID)
+	}
+
+	var p postedTransaction
+
+	d := json.NewDecoder(r.Body)
+	d.DisallowUnknownFields()
+	if err := d.Decode(&p); err != nil {
+		return nil, errorf(http.StatusBadRequest, "decoding transaction: %s", err)
+	}
+
+	date, err := time.Parse(internal.DateLayout, p.Date)
+	if err != nil {
+		return nil, errorf(http.StatusBadRequest, "parsing date: %s", err)
+	}
+
+	amount, err := io.ParseAmount(p.Amount)
+	if err != nil {
+		return nil, errorf(http.StatusBadRequest, "parsing amount: %s", err)
+	}
+
+	t := io.Transaction{
+		Date:        date,
+		Type:        p.Type,
+		Description: p.Description,
+		Amount:      amount,
+		Currency:    p.Currency,
+	}
+	if t.Type == "" {
+		t.Type = "Insättning"
+	}
+	if t.Currency == "" {
+		t.Currency = "SEK"
+	}
+
+	e := io.LedgerEntry{Transaction: t, Source: apiSource, Imported: s.now().UTC()}
+	if p.Kind != "" {
+		e.Kind, err = io.ParseKind(p.Kind)
+		if err != nil {
+			return nil, errorf(http.StatusBadRequest, "parsing kind: %s", err)
+		}
+		e.Classified = true
+	}
+
+	s.mu.Lock()
+	err = io.Append(loan.Inputs.Ledger, []io.LedgerEntry{e})
+	s.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	return created{map[string]int{"added": 1}}, nil
+}
@@ -0,0 +1,294 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/calc"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/input"
+)
+
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	testdata, err := filepath.Abs(filepath.Join("..", "testdata"))
+	if err != nil {
+		t.Fatalf("resolving testdata: %s", err)
+	}
+
+	config := filepath.Join(t.TempDir(), "loans.json")
+	err = os.WriteFile(config, []byte(`{
+	  "loans": [
+	    {
+	      "id": "home",
+	      "firstDay": "2022-06-07",
+	      "principal": "100000",
+	      "transactions": "`+filepath.Join(testdata, "transactions.csv")+`",
+	      "interestRates": "`+filepath.Join(testdata, "annual_interest_rates.csv")+`"
+	    },
+	    {
+	      "id": "cabin",
+	      "firstDay": "2022-06-07",
+	      "principal": "100000",
+	      "ledger": "ledger.jsonl",
+	      "interestRates": "`+filepath.Join(testdata, "annual_interest_rates.csv")+`"
+	    }
+	  ]
+	}`), 0o644)
+	if err != nil {
+		t.Fatalf("writing configuration: %s", err)
+	}
+
+	loans, err := ReadConfig(config)
+	if err != nil {
+		t.Fatalf("reading configuration: %s", err)
+	}
+
+	s := New(loans)
+	s.now = func() time.Time { return time.Date(2023, 1, 15, 12, 0, 0, 0, time.UTC) }
+
+	ts := httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+
+	return ts
+}
+
+// get requests path from ts, checks the status code of the response,
+// and decodes its body into v.
+func get(t *testing.T, ts *httptest.Server, path string, wantStatus int, v interface{}) {
+	t.Helper()
+
+	resp, err := http.Get(ts.URL + path)
+	if err != nil {
+		t.Fatalf("GET %s: %s", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != wantStatus {
+		t.Fatalf("GET %s: want status %d, but got %d", path, wantStatus, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("GET %s: decoding response: %s", path, err)
+	}
+}
+
+func TestLoans(t *testing.T) {
+	ts := newTestServer(t)
+
+	var ids []string
+	get(t, ts, "/loans", http.StatusOK, &ids)
+
+	if want, got := "cabin,home", strings.Join(ids, ","); want != got {
+		t.Errorf("want loans %s, but got %s", want, got)
+	}
+}
+
+func TestBalance(t *testing.T) {
+	ts := newTestServer(t)
+
+	var state dayState
+	get(t, ts, "/loans/home/balance?date=2022-08-10", http.StatusOK, &state)
+
+	if want, got := "97202.14", state.Balance; want != got {
+		t.Errorf("want balance %s, but got %s", want, got)
+	}
+	if want, got := "2022-08-10", state.Date; want != got {
+		t.Errorf("want date %s, but got %s", want, got)
+	}
+
+	get(t, ts, "/loans/home/balance", http.StatusOK, &state)
+	if want, got := "2023-01-15", state.Date; want != got {
+		t.Errorf("want date today %s, but got %s", want, got)
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	ts := newTestServer(t)
+
+	var states []dayState
+	get(t, ts, "/loans/home/schedule?from=2022-08-01&to=2022-08-31", http.StatusOK, &states)
+
+	if want, got := 31, len(states); want != got {
+		t.Fatalf("want %d days, but got %d", want, got)
+	}
+	if want, got := "97202.14", states[9].Balance; want != got {
+		t.Errorf("want balance %s on %s, but got %s", want, states[9].Date, got)
+	}
+}
+
+func TestYearly(t *testing.T) {
+	ts := newTestServer(t)
+
+	var years []yearSummary
+	get(t, ts, "/loans/home/summary/yearly", http.StatusOK, &years)
+
+	if want, got := 2, len(years); want != got {
+		t.Fatalf("want %d years, but got %d", want, got)
+	}
+	if want, got := 2023, years[1].Year; want != got {
+		t.Errorf("want year %d, but got %d", want, got)
+	}
+	if want, got := "2023-01-15", years[1].To; want != got {
+		t.Errorf("want last day %s, but got %s", want, got)
+	}
+}
+
+func TestAddTransaction(t *testing.T) {
+	ts := newTestServer(t)
+
+	post := func(body string) int {
+		resp, err := http.Post(ts.URL+"/loans/cabin/transactions", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST: %s", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	payment := `{"date": "2022-07-01", "amount": "3000", "description": "Amortering"}`
+
+	// A second identical payment is a payment of its own.
+	for i := 0; i < 2; i++ {
+		if want, got := http.StatusCreated, post(payment); want != got {
+			t.Errorf("want status %d, but got %d", want, got)
+		}
+	}
+	if want, got := http.StatusBadRequest, post(`{"date": "2022-07-01", "amount": "lots"}`); want != got {
+		t.Errorf("want status %d for a bad amount, but got %d", want, got)
+	}
+	if want, got := http.StatusBadRequest, post(`{"description": "`+strings.Repeat("x", maxBodySize)+`"}`); want != got {
+		t.Errorf("want status %d for a too large body, but got %d", want, got)
+	}
+	if want, got := http.StatusBadRequest, post(`{"date": "2022-07-01", "amount": "50", "kind": "gift"}`); want != got {
+		t.Errorf("want status %d for a bad kind, but got %d", want, got)
+	}
+
+	var state dayState
+	get(t, ts, "/loans/cabin/balance?date=2022-07-01", http.StatusOK, &state)
+
+	// The interest of June, 76.00, is capitalized on the same day.
+	if want, got := "94076.00", state.Balance; want != got {
+		t.Errorf("want balance %s, but got %s", want, got)
+	}
+
+	// A deposit of the fee kind is charged as a fee, which the
+	// payments settle before the balance.
+	if want, got := http.StatusCreated, post(`{"date": "2022-07-01", "amount": "50", "kind": "fee"}`); want != got {
+		t.Errorf("want status %d, but got %d", want, got)
+	}
+	get(t, ts, "/loans/cabin/balance?date=2022-07-01", http.StatusOK, &state)
+	if want, got := "94126.00", state.Balance; want != got {
+		t.Errorf("want balance %s, but got %s", want, got)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	ts := newTestServer(t)
+
+	tests := []struct {
+		method, path string
+		wantStatus   int
+	}{
+		{http.MethodGet, "/other", http.StatusNotFound},
+		{http.MethodGet, "/loans/none/balance", http.StatusNotFound},
+		{http.MethodGet, "/loans/home/other", http.StatusNotFound},
+		{http.MethodPost, "/loans/home/balance", http.StatusMethodNotAllowed},
+		{http.MethodGet, "/loans/home/balance?date=yesterday", http.StatusBadRequest},
+		{http.MethodGet, "/loans/home/balance?date=2022-01-01", http.StatusBadRequest},
+		{http.MethodGet, "/loans/home/schedule?to=9999-12-31", http.StatusBadRequest},
+		{http.MethodPost, "/loans/home/transactions", http.StatusConflict},
+	}
+
+	for _, tt := range tests {
+		req, err := http.NewRequest(tt.method, ts.URL+tt.path, strings.NewReader("{}"))
+		if err != nil {
+			t.Fatalf("creating request: %s", err)
+		}
+
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("%s %s: %s", tt.method, tt.path, err)
+		}
+
+		var body map[string]string
+		json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.wantStatus {
+			t.Errorf("%s %s: want status %d, but got %d", tt.method, tt.path, tt.wantStatus, resp.StatusCode)
+		}
+		if body["error"] == "" {
+			t.Errorf("%s %s: want an error message", tt.method, tt.path)
+		}
+	}
+}
+
+// TestBalance_Inputs checks that the inputs beyond transactions and
+// rates are loaded like by the commands, so that the balance served is
+// the same as the one calculated by them.
+func TestBalance_Inputs(t *testing.T) {
+	testdata, err := filepath.Abs(filepath.Join("..", "testdata"))
+	if err != nil {
+		t.Fatalf("resolving testdata: %s", err)
+	}
+
+	config := filepath.Join(t.TempDir(), "loans.json")
+	err = os.WriteFile(config, []byte(`{
+	  "loans": [
+	    {
+	      "id": "home",
+	      "firstDay": "2022-06-07",
+	      "principal": "100000",
+	      "transactions": "`+filepath.Join(testdata, "transactions.csv")+`",
+	      "kindRules": "`+filepath.Join(testdata, "kind_rules.csv")+`",
+	      "interestRates": "`+filepath.Join(testdata, "annual_interest_rates.csv")+`",
+	      "fees": "`+filepath.Join(testdata, "fees.csv")+`"
+	    }
+	  ]
+	}`), 0o644)
+	if err != nil {
+		t.Fatalf("writing configuration: %s", err)
+	}
+
+	loans, err := ReadConfig(config)
+	if err != nil {
+		t.Fatalf("reading configuration: %s", err)
+	}
+
+	flags := input.DefaultFlags()
+	flags.FirstDay = "2022-06-07"
+	flags.Principal = "100000"
+	flags.Transactions = filepath.Join(testdata, "transactions.csv")
+	flags.KindRules = filepath.Join(testdata, "kind_rules.csv")
+	flags.InterestRates = filepath.Join(testdata, "annual_interest_rates.csv")
+	flags.Fees = filepath.Join(testdata, "fees.csv")
+
+	in, err := flags.Load()
+	if err != nil {
+		t.Fatalf("loading inputs: %s", err)
+	}
+	bank := in.NewBank()
+	date := time.Date(2022, 12, 31, 0, 0, 0, 0, time.UTC)
+
+	days, err := calc.Series(&bank, calc.NewLoan(in.Principal), in.FirstDay, date)
+	if err != nil {
+		t.Fatalf("calculating loan: %s", err)
+	}
+	want := newDayState(days[len(days)-1])
+
+	ts := httptest.NewServer(New(loans))
+	t.Cleanup(ts.Close)
+
+	var got dayState
+	get(t, ts, "/loans/home/balance?date=2022-12-31", http.StatusOK, &got)
+
+	if want != got {
+		t.Errorf("want %+v, but got %+v", want, got)
+	}
+}
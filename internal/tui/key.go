@@ -0,0 +1,94 @@
+package tui
+
+import "unicode/utf8"
+
+// Key is a key pressed on the terminal: either a rune, or one of the
+// special keys below, which are negative.
+type Key rune
+
+const (
+	KeyUp Key = -(iota + 1)
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyPageUp
+	KeyPageDown
+	KeyHome
+	KeyEnd
+	KeyEscape
+)
+
+const (
+	keyCtrlC     Key = 0x03
+	keyEnter     Key = '\r'
+	keyBackspace Key = 0x7f
+)
+
+// escapeSequences maps the escape sequences sent by common terminals,
+// without the leading ESC, to their keys.
+var escapeSequences = map[string]Key{
+	"[A":  KeyUp,
+	"[B":  KeyDown,
+	"[C":  KeyRight,
+	"[D":  KeyLeft,
+	"OA":  KeyUp,
+	"OB":  KeyDown,
+	"OC":  KeyRight,
+	"OD":  KeyLeft,
+	"[5~": KeyPageUp,
+	"[6~": KeyPageDown,
+	"[H":  KeyHome,
+	"[F":  KeyEnd,
+	"OH":  KeyHome,
+	"OF":  KeyEnd,
+	"[1~": KeyHome,
+	"[4~": KeyEnd,
+}
+
+// decodeKeys decodes the keys of input read from a terminal in raw
+// mode. Unknown escape sequences are dropped, and an ESC that does not
+// start a known sequence is decoded as KeyEscape.
+func decodeKeys(b []byte) []Key {
+	var keys []Key
+
+	for len(b) > 0 {
+		if b[0] == 0x1b {
+			n := escapeLength(b[1:])
+			if k, ok := escapeSequences[string(b[1:1+n])]; ok && n > 0 {
+				keys = append(keys, k)
+			} else if n == 0 {
+				keys = append(keys, KeyEscape)
+			}
+			b = b[1+n:]
+			continue
+		}
+
+		r, size := utf8.DecodeRune(b)
+		if r == '\n' {
+			r = '\r'
+		}
+		keys = append(keys, Key(r))
+		b = b[size:]
+	}
+
+	return keys
+}
+
+// escapeLength returns the length of the escape sequence at the start
+// of b, which follows an ESC, or 0 if there is none.
+func escapeLength(b []byte) int {
+	if len(b) < 2 || (b[0] != '[' && b[0] != 'O') {
+		return 0
+	}
+	if b[0] == 'O' {
+		return 2
+	}
+
+	// A CSI sequence ends with a byte in the range @ to ~.
+	for i := 1; i < len(b); i++ {
+		if b[i] >= '@' && b[i] <= '~' {
+			return i + 1
+		}
+	}
+	return len(b)
+}
@@ -0,0 +1,28 @@
+package tui
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDecodeKeys(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []Key
+	}{
+		{"j", []Key{'j'}},
+		{"\x1b[A\x1b[B", []Key{KeyUp, KeyDown}},
+		{"\x1bOD", []Key{KeyLeft}},
+		{"\x1b[5~\x1b[6~", []Key{KeyPageUp, KeyPageDown}},
+		{"\x1b", []Key{KeyEscape}},
+		{"\x1b[99~q", []Key{'q'}},
+		{"2023-01\r", []Key{'2', '0', '2', '3', '-', '0', '1', keyEnter}},
+		{"ö", []Key{'ö'}},
+	}
+
+	for _, tt := range tests {
+		if got := decodeKeys([]byte(tt.in)); !reflect.DeepEqual(tt.want, got) {
+			t.Errorf("decodeKeys(%q): want %v, but got %v", tt.in, tt.want, got)
+		}
+	}
+}
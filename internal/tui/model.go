@@ -0,0 +1,255 @@
+// Package tui is an interactive terminal UI for exploring the days of
+// a loan: a table of the days that can be folded into months and
+// years, a sparkline of the balance, and the details of the selected
+// day.
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/calc"
+)
+
+// level is the level of a row of the table.
+type level int
+
+const (
+	levelYear level = iota
+	levelMonth
+	levelDay
+)
+
+// row is a row of the table: a year or month, which can be unfolded
+// into its months or days, or a day.
+type row struct {
+	level level
+	// first and last are the indices of the first and last days of
+	// the row.
+	first, last int
+}
+
+// period identifies a year, with a zero month, or a month.
+type period struct {
+	year  int
+	month time.Month
+}
+
+func (r row) period(days []calc.Day) period {
+	y, m, _ := days[r.first].Date.Date()
+	if r.level == levelYear {
+		m = 0
+	}
+	return period{y, m}
+}
+
+// Model is the state of the UI. It is changed by [Model.Update] and
+// drawn by [Model.View].
+type Model struct {
+	days []calc.Day
+	// unfolded holds the years and months whose rows are unfolded.
+	unfolded map[period]bool
+	rows     []row
+	// cursor is the index of the selected row, and offset the index
+	// of the first row shown.
+	cursor, offset int
+	// pageSize is the number of rows shown by the last View.
+	pageSize int
+
+	// jumping is true while a date to jump to is entered into input.
+	jumping bool
+	input   string
+	// message is shown in the status line until the next key.
+	message string
+}
+
+// NewModel returns a model of days, which must not be empty, with the
+// last day selected.
+func NewModel(days []calc.Day) *Model {
+	m := &Model{days: days, unfolded: make(map[period]bool), pageSize: 10}
+	m.jumpTo(len(days) - 1)
+	return m
+}
+
+// selected returns the index of the day of the selected row. A year or
+// month is represented by its last day.
+func (m *Model) selected() int {
+	return m.rows[m.cursor].last
+}
+
+// rebuild rebuilds the rows after a fold has changed, and selects the
+// row of the given level and first day, or else the deepest row that
+// includes that day.
+func (m *Model) rebuild(l level, first int) {
+	m.rows = m.rows[:0]
+
+	for i := 0; i < len(m.days); {
+		year := m.span(i, func(d time.Time) int { return d.Year() })
+		m.rows = append(m.rows, row{levelYear, i, year})
+
+		if m.unfolded[period{m.days[i].Date.Year(), 0}] {
+			for j := i; j <= year; {
+				month := m.span(j, func(d time.Time) int { return int(d.Month()) })
+				m.rows = append(m.rows, row{levelMonth, j, month})
+
+				if m.unfolded[period{m.days[j].Date.Year(), m.days[j].Date.Month()}] {
+					for k := j; k <= month; k++ {
+						m.rows = append(m.rows, row{levelDay, k, k})
+					}
+				}
+				j = month + 1
+			}
+		}
+		i = year + 1
+	}
+
+	for i, r := range m.rows {
+		if r.first <= first && first <= r.last {
+			m.cursor = i
+			if r.level == l && r.first == first {
+				break
+			}
+		}
+	}
+}
+
+// span returns the index of the last day from i on for which key
+// returns the same as for day i.
+func (m *Model) span(i int, key func(time.Time) int) int {
+	k := key(m.days[i].Date)
+	j := i
+	for j+1 < len(m.days) && key(m.days[j+1].Date) == k {
+		j++
+	}
+	return j
+}
+
+// jumpTo unfolds the year and month of day i and selects it.
+func (m *Model) jumpTo(i int) {
+	y, mo, _ := m.days[i].Date.Date()
+	m.unfolded[period{y, 0}] = true
+	m.unfolded[period{y, mo}] = true
+	m.rebuild(levelDay, i)
+}
+
+// fold folds or unfolds the selected year or month.
+func (m *Model) fold(unfold bool) {
+	r := m.rows[m.cursor]
+	if r.level == levelDay {
+		return
+	}
+	m.unfolded[r.period(m.days)] = unfold
+	m.rebuild(r.level, r.first)
+}
+
+// parent folds the selected row into its parent, or selects the parent
+// of a folded year or month.
+func (m *Model) parent() {
+	r := m.rows[m.cursor]
+	switch {
+	case r.level != levelDay && m.unfolded[r.period(m.days)]:
+		m.fold(false)
+	case r.level == levelDay:
+		y, mo, _ := m.days[r.first].Date.Date()
+		m.unfolded[period{y, mo}] = false
+		m.rebuild(levelMonth, r.first)
+	case r.level == levelMonth:
+		y := m.days[r.first].Date.Year()
+		m.unfolded[period{y, 0}] = false
+		m.rebuild(levelYear, r.first)
+	}
+}
+
+// move moves the cursor by n rows, within the table.
+func (m *Model) move(n int) {
+	m.cursor += n
+	if m.cursor < 0 {
+		m.cursor = 0
+	}
+	if m.cursor >= len(m.rows) {
+		m.cursor = len(m.rows) - 1
+	}
+}
+
+// Update changes the model according to a pressed key, and returns
+// true if the user quit.
+func (m *Model) Update(k Key) bool {
+	m.message = ""
+
+	if m.jumping {
+		m.updateJump(k)
+		return false
+	}
+
+	switch k {
+	case 'q', keyCtrlC:
+		return true
+	case KeyUp, 'k':
+		m.move(-1)
+	case KeyDown, 'j':
+		m.move(1)
+	case KeyPageUp:
+		m.move(-m.pageSize)
+	case KeyPageDown:
+		m.move(m.pageSize)
+	case KeyHome, 'g':
+		m.move(-len(m.rows))
+	case KeyEnd, 'G':
+		m.move(len(m.rows))
+	case keyEnter, ' ':
+		r := m.rows[m.cursor]
+		if r.level != levelDay {
+			m.fold(!m.unfolded[r.period(m.days)])
+		}
+	case KeyRight, 'l':
+		m.fold(true)
+	case KeyLeft, 'h':
+		m.parent()
+	case '/':
+		m.jumping = true
+		m.input = ""
+	}
+
+	return false
+}
+
+// updateJump changes the date being entered, and jumps to it on enter.
+func (m *Model) updateJump(k Key) {
+	switch {
+	case k == KeyEscape || k == keyCtrlC:
+		m.jumping = false
+	case k == keyBackspace:
+		if m.input != "" {
+			m.input = m.input[:len(m.input)-1]
+		}
+	case k == keyEnter:
+		m.jumping = false
+		if err := m.jump(m.input); err != nil {
+			m.message = err.Error()
+		}
+	case (k >= '0' && k <= '9') || k == '-':
+		if len(m.input) < len(internal.DateLayout) {
+			m.input += string(rune(k))
+		}
+	}
+}
+
+// jump selects the day of date, formatted as YYYY-MM-DD.
+func (m *Model) jump(date string) error {
+	t, err := time.Parse(internal.DateLayout, date)
+	if err != nil {
+		return fmt.Errorf("not a date: %q", date)
+	}
+
+	i := sort.Search(len(m.days), func(i int) bool {
+		return !m.days[i].Date.Before(t)
+	})
+	if i == len(m.days) || !m.days[i].Date.Equal(t) {
+		return fmt.Errorf("%s is not a day of the loan", date)
+	}
+
+	m.jumpTo(i)
+	return nil
+}
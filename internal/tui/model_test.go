@@ -0,0 +1,133 @@
+package tui
+
+import (
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/calc"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+func testDays(t *testing.T) []calc.Day {
+	t.Helper()
+
+	transactions, err := io.ReadTransactions(filepath.Join("..", "testdata", "transactions.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading transactions: %s", err)
+	}
+	rates, err := io.ReadInterestRates(filepath.Join("..", "testdata", "annual_interest_rates.csv"), ';')
+	if err != nil {
+		t.Fatalf("reading interest rates: %s", err)
+	}
+
+	bank := calc.NewBank(transactions, rates)
+	first := time.Date(2022, 6, 7, 0, 0, 0, 0, time.UTC)
+	last := time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	days, err := calc.Series(&bank, calc.NewLoan(big.NewRat(100000, 1)), first, last)
+	if err != nil {
+		t.Fatalf("calculating days: %s", err)
+	}
+	return days
+}
+
+// update sends keys to m.
+func update(m *Model, keys string) {
+	for _, k := range decodeKeys([]byte(keys)) {
+		m.Update(k)
+	}
+}
+
+func selectedDate(m *Model) string {
+	return m.days[m.selected()].Date.Format("2006-01-02")
+}
+
+func TestModel_Fold(t *testing.T) {
+	m := NewModel(testDays(t))
+
+	// The year and month of the last day are unfolded: 2022, 2023,
+	// 2023-01, and its 15 days.
+	if want, got := 18, len(m.rows); want != got {
+		t.Fatalf("want %d rows, but got %d", want, got)
+	}
+	if want, got := "2023-01-15", selectedDate(m); want != got {
+		t.Errorf("want %s selected, but got %s", want, got)
+	}
+
+	// Fold the month, and then the year, into their parents.
+	update(m, "hh")
+	if want, got := 2, len(m.rows); want != got {
+		t.Fatalf("want %d rows, but got %d", want, got)
+	}
+	if want, got := levelYear, m.rows[m.cursor].level; want != got {
+		t.Errorf("want level %d selected, but got %d", want, got)
+	}
+
+	// Unfold 2022 into its seven months, and select August.
+	update(m, "k\r")
+	if want, got := 9, len(m.rows); want != got {
+		t.Fatalf("want %d rows, but got %d", want, got)
+	}
+	update(m, "jjj")
+	if want, got := "2022-08-31", selectedDate(m); want != got {
+		t.Errorf("want %s selected, but got %s", want, got)
+	}
+}
+
+func TestModel_Jump(t *testing.T) {
+	m := NewModel(testDays(t))
+
+	update(m, "/2022-08-10\r")
+	if want, got := "2022-08-10", selectedDate(m); want != got {
+		t.Errorf("want %s selected, but got %s", want, got)
+	}
+	if want, got := levelDay, m.rows[m.cursor].level; want != got {
+		t.Errorf("want level %d selected, but got %d", want, got)
+	}
+
+	update(m, "/2021-01-01\r")
+	if want, got := "2022-08-10", selectedDate(m); want != got {
+		t.Errorf("want %s still selected, but got %s", want, got)
+	}
+	if !strings.Contains(m.message, "not a day of the loan") {
+		t.Errorf("want a message about the date, but got %q", m.message)
+	}
+
+	update(m, "/2022\x1b")
+	if m.jumping {
+		t.Errorf("want jumping cancelled")
+	}
+}
+
+func TestModel_View(t *testing.T) {
+	m := NewModel(testDays(t))
+	update(m, "/2022-08-10\r")
+
+	view := m.View(120, 30)
+	lines := strings.Split(view, "\n")
+
+	if want, got := 30, len(lines); want != got {
+		t.Fatalf("want %d lines, but got %d", want, got)
+	}
+
+	for _, want := range []string{
+		reverse + "    08-10         1.64     97202.14       4.29     97246.19",
+		" Balance                     97202.14",
+		" Insättning                  3003.90",
+	} {
+		if !strings.Contains(view, want) {
+			t.Errorf("want %q in the view:\n%s", want, view)
+		}
+	}
+	if !strings.HasPrefix(lines[1], "Balance ▇") || !strings.Contains(lines[1], reverse) {
+		t.Errorf("want a sparkline with the selected day highlighted, but got %q", lines[1])
+	}
+
+	update(m, "/2022-08-01\r")
+	if view := m.View(120, 30); !strings.Contains(view, "Capitalized") {
+		t.Errorf("want the capitalized interest on the first day of a month:\n%s", view)
+	}
+}
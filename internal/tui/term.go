@@ -0,0 +1,97 @@
+package tui
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/calc"
+)
+
+// ANSI escape sequences controlling the terminal.
+const (
+	enterAltScreen = "\x1b[?1049h\x1b[?25l"
+	leaveAltScreen = "\x1b[?25h\x1b[?1049l"
+	home           = "\x1b[H"
+	clearLine      = "\x1b[K"
+	clearBelow     = "\x1b[J"
+)
+
+// Run runs the UI for days, which must not be empty, on the terminal
+// of in and out until the user quits. The terminal is put in raw mode
+// with stty(1) and restored before Run returns.
+func Run(days []calc.Day, in *os.File, out io.Writer) (err error) {
+	state, err := stty(in, "-g")
+	if err != nil {
+		return fmt.Errorf("saving terminal state: %w", err)
+	}
+	if _, err := stty(in, "raw", "-echo"); err != nil {
+		return fmt.Errorf("setting terminal to raw mode: %w", err)
+	}
+	defer func() {
+		if _, restoreErr := stty(in, strings.TrimSpace(state)); restoreErr != nil && err == nil {
+			err = fmt.Errorf("restoring terminal state: %w", restoreErr)
+		}
+	}()
+
+	fmt.Fprint(out, enterAltScreen)
+	defer fmt.Fprint(out, leaveAltScreen)
+
+	m := NewModel(days)
+	buf := make([]byte, 64)
+
+	for {
+		width, height, err := size(in)
+		if err != nil {
+			return err
+		}
+
+		view := strings.ReplaceAll(m.View(width, height), "\n", clearLine+"\r\n")
+		if _, err := fmt.Fprint(out, home+view+clearLine+clearBelow); err != nil {
+			return fmt.Errorf("writing to terminal: %w", err)
+		}
+
+		n, err := in.Read(buf)
+		if err != nil {
+			return fmt.Errorf("reading from terminal: %w", err)
+		}
+
+		for _, k := range decodeKeys(buf[:n]) {
+			if m.Update(k) {
+				return nil
+			}
+		}
+	}
+}
+
+// stty runs stty(1) with args on the terminal of in and returns its
+// output.
+func stty(in *os.File, args ...string) (string, error) {
+	var stderr bytes.Buffer
+
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = in
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// size returns the width and height of the terminal of in.
+func size(in *os.File) (width, height int, err error) {
+	out, err := stty(in, "size")
+	if err != nil {
+		return 0, 0, fmt.Errorf("getting terminal size: %w", err)
+	}
+
+	if _, err := fmt.Sscan(out, &height, &width); err != nil {
+		return 0, 0, fmt.Errorf("parsing terminal size %q: %w", out, err)
+	}
+	return width, height, nil
+}
@@ -0,0 +1,280 @@
+package tui
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"unicode/utf8"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/calc"
+)
+
+const (
+	// reverse and reset are the ANSI escape sequences that start and
+	// end the highlighting of the selection.
+	reverse = "\x1b[7m"
+	reset   = "\x1b[0m"
+
+	// panelWidth is the width of the side panel, which is only shown
+	// if the terminal is at least minPanelWidth wide.
+	panelWidth    = 38
+	minPanelWidth = 100
+
+	help = "↑↓ move  ←→/⏎ fold  / go to date  q quit"
+)
+
+// sparks are the characters of a sparkline, from the lowest to the
+// highest value.
+var sparks = []rune("▁▂▃▄▅▆▇█")
+
+// View draws the model on a terminal of the given size, as lines
+// separated by "\n".
+func (m *Model) View(width, height int) string {
+	tableWidth := width
+	if width >= minPanelWidth {
+		tableWidth = width - panelWidth - 1
+	}
+
+	// The title, sparkline, and table header are above the table, and
+	// the status line below it.
+	m.pageSize = height - 4
+	if m.pageSize < 1 {
+		m.pageSize = 1
+	}
+	m.scroll()
+
+	first, last := m.days[0].Date, m.days[len(m.days)-1].Date
+	lines := []string{
+		fit(fmt.Sprintf("Loan from %s through %s", first.Format(internal.DateLayout), last.Format(internal.DateLayout)), width),
+		m.sparkline(width),
+	}
+
+	table := []string{fit(fmt.Sprintf("%-14s %7s %12s %10s %12s", "Date", "Rate", "Balance", "Interest", "Owed"), tableWidth)}
+	for i := m.offset; i < m.offset+m.pageSize; i++ {
+		if i >= len(m.rows) {
+			table = append(table, fit("", tableWidth))
+			continue
+		}
+		line := fit(m.rowText(m.rows[i]), tableWidth)
+		if i == m.cursor {
+			line = reverse + line + reset
+		}
+		table = append(table, line)
+	}
+
+	if tableWidth < width {
+		panel := m.panel()
+		for i := range table {
+			p := ""
+			if i < len(panel) {
+				p = panel[i]
+			}
+			table[i] += "│" + fit(p, panelWidth)
+		}
+	}
+	lines = append(lines, table...)
+
+	switch {
+	case m.jumping:
+		lines = append(lines, fit("Go to date: "+m.input+"_", width))
+	case m.message != "":
+		lines = append(lines, fit(m.message, width))
+	default:
+		lines = append(lines, fit(help, width))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// scroll scrolls the table to show the selected row.
+func (m *Model) scroll() {
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+m.pageSize {
+		m.offset = m.cursor - m.pageSize + 1
+	}
+	if max := len(m.rows) - m.pageSize; m.offset > max && max >= 0 {
+		m.offset = max
+	}
+}
+
+// rowText returns the text of r in the table. A year or month shows the
+// state at the end of its last day, and the interest accrued during it.
+func (m *Model) rowText(r row) string {
+	day := m.days[r.last]
+
+	var label string
+	switch r.level {
+	case levelYear:
+		label = fmt.Sprintf("%s %d", m.marker(r), day.Date.Year())
+	case levelMonth:
+		label = fmt.Sprintf("  %s %s", m.marker(r), day.Date.Format("2006-01"))
+	default:
+		label = "    " + day.Date.Format("01-02")
+	}
+
+	interest := new(big.Rat)
+	for _, d := range m.days[r.first : r.last+1] {
+		interest.Add(interest, d.Interest)
+	}
+
+	return fmt.Sprintf("%-14s %7s %12s %10s %12s",
+		label,
+		rate(day),
+		day.Loan.Balance().FloatString(2),
+		interest.FloatString(2),
+		day.Loan.Owed().FloatString(2),
+	)
+}
+
+// marker returns the marker of a folded or unfolded year or month.
+func (m *Model) marker(r row) string {
+	if m.unfolded[r.period(m.days)] {
+		return "▾"
+	}
+	return "▸"
+}
+
+// rate returns the annual interest rate of day in percent, or "-".
+func rate(day calc.Day) string {
+	if day.Rate == nil {
+		return "-"
+	}
+	return new(big.Rat).Mul(day.Rate, big.NewRat(100, 1)).FloatString(2)
+}
+
+// sparkline returns a sparkline of the balance over all days, with the
+// selected day highlighted.
+func (m *Model) sparkline(width int) string {
+	const label = "Balance "
+
+	n := width - len(label)
+	if n < 1 {
+		return fit(label, width)
+	}
+	if n > len(m.days) {
+		n = len(m.days)
+	}
+
+	// column returns the index of the day shown in column c.
+	column := func(c int) int {
+		if n == 1 {
+			return len(m.days) - 1
+		}
+		return c * (len(m.days) - 1) / (n - 1)
+	}
+
+	values := make([]float64, n)
+	lo, hi := 0.0, 0.0
+	for c := range values {
+		values[c], _ = m.days[column(c)].Loan.Balance().Float64()
+		if c == 0 || values[c] < lo {
+			lo = values[c]
+		}
+		if c == 0 || values[c] > hi {
+			hi = values[c]
+		}
+	}
+
+	// The selected day is highlighted in the last column showing a day
+	// on or before it.
+	selected, sel := 0, m.selected()
+	for c := range values {
+		if column(c) <= sel {
+			selected = c
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(label)
+	for c, v := range values {
+		level := 0
+		if hi > lo {
+			level = int((v - lo) / (hi - lo) * float64(len(sparks)-1))
+		}
+		if c == selected {
+			b.WriteString(reverse + string(sparks[level]) + reset)
+		} else {
+			b.WriteRune(sparks[level])
+		}
+	}
+	b.WriteString(strings.Repeat(" ", width-len(label)-n))
+
+	return b.String()
+}
+
+// panel returns the lines of the side panel, which details the selected
+// day: its rate, the state of the loan, what was charged and
+// capitalized, and its transactions.
+func (m *Model) panel() []string {
+	i := m.selected()
+	day := m.days[i]
+
+	field := func(name, value string) string {
+		return fmt.Sprintf(" %-18s %17s", name, value)
+	}
+
+	lines := []string{
+		" " + day.Date.Format("Monday 2006-01-02"),
+		"",
+		field("Interest rate (%)", rate(day)),
+		field("Balance", day.Loan.Balance().FloatString(2)),
+		field("Accrued interest", day.Loan.Interest().FloatString(2)),
+		field("Unpaid fees", day.Loan.Fees().FloatString(2)),
+		field("Overdue", day.Loan.Overdue().FloatString(2)),
+		field("Penalty interest", day.Loan.PenaltyInterest().FloatString(2)),
+		field("Surplus", day.Loan.Surplus().FloatString(2)),
+		field("Owed", day.Loan.Owed().FloatString(2)),
+		"",
+		field("Interest today", day.Interest.FloatString(2)),
+	}
+
+	if day.Fees.Sign() != 0 {
+		lines = append(lines, field("Fees charged", day.Fees.FloatString(2)))
+	}
+	if c := m.capitalized(i); c != nil {
+		lines = append(lines, field("Capitalized", c.FloatString(2)))
+	}
+	if day.Due != nil {
+		lines = append(lines, field("Minimum payment", day.Due.Minimum.FloatString(2)+" "+day.Due.Status()))
+	}
+	if day.PaidOff {
+		lines = append(lines, " Paid off")
+	}
+
+	lines = append(lines, "", " Transactions")
+	if len(day.Transactions) == 0 {
+		lines = append(lines, "  none")
+	}
+	for _, t := range day.Transactions {
+		lines = append(lines,
+			field(" "+t.Type, t.Amount.FloatString(2)),
+			"   "+t.Description,
+		)
+	}
+
+	return lines
+}
+
+// capitalized returns the interest capitalized at the start of day i,
+// the first day of a month, or nil if none was.
+func (m *Model) capitalized(i int) *big.Rat {
+	if i == 0 || m.days[i].Date.Day() != 1 {
+		return nil
+	}
+	return m.days[i-1].Loan.Interest()
+}
+
+// fit pads or truncates s to exactly width runes.
+func fit(s string, width int) string {
+	if width <= 0 {
+		return ""
+	}
+	n := utf8.RuneCountInString(s)
+	if n > width {
+		return string([]rune(s)[:width])
+	}
+	return s + strings.Repeat(" ", width-n)
+}
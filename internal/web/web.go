@@ -0,0 +1,319 @@
+// Package web serves a web UI for calculating a loan from uploaded
+// bank statements and interest rates, for those who do not use the
+// command line. All of its files are embedded in the binary.
+package web
+
+import (
+	"bytes"
+	"embed"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/calc"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/input"
+	intio "gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// maxUploadSize is the maximum size in bytes of the uploaded files of
+// a calculation.
+const maxUploadSize = 10 << 20
+
+//go:embed templates/*.html static/*
+var files embed.FS
+
+var templates = template.Must(template.ParseFS(files, "templates/*.html"))
+
+// form is the input of a calculation, as entered in the form.
+type form struct {
+	FirstDay    string
+	Principal   string
+	Comma       string
+	Overpayment string
+}
+
+// page is the data of the page template.
+type page struct {
+	Form  form
+	Error string
+	// Result is nil until a calculation has been made.
+	Result *result
+}
+
+// result is the outcome of a calculation: the output of [calc.Run]
+// and [calc.RunCost] as tables, and a chart of the balance.
+type result struct {
+	Days  table
+	Costs table
+	Chart chart
+}
+
+// table is a CSV output with its header separated from the records.
+type table struct {
+	Header  []string
+	Records [][]string
+}
+
+// New returns a handler serving the web UI.
+func New() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/static/", http.FileServer(http.FS(files)))
+	mux.HandleFunc("/", index)
+	return mux
+}
+
+// index shows the form on GET, and the form with the result of the
+// calculation on POST.
+func index(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+
+	p := page{Form: form{
+		FirstDay:    "2022-06-27",
+		Principal:   "200000",
+		Comma:       ";",
+		Overpayment: input.DefaultFlags().Overpayment,
+	}}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPost:
+		r.Body = http.MaxBytesReader(w, r.Body, maxUploadSize)
+		p.Form = form{
+			FirstDay:    r.FormValue("firstDay"),
+			Principal:   r.FormValue("principal"),
+			Comma:       r.FormValue("comma"),
+			Overpayment: r.FormValue("overpayment"),
+		}
+		var err error
+		p.Result, err = calculate(r)
+		if err != nil {
+			p.Error = err.Error()
+		}
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := templates.ExecuteTemplate(&buf, "index.html", p); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	buf.WriteTo(w)
+}
+
+// calculate runs the calculations for the loan of the posted form.
+// The uploaded files and entered values are loaded as the inputs of
+// the commands (see [input.Flags]), so that the same files give the
+// same results.
+func calculate(r *http.Request) (*result, error) {
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		return nil, fmt.Errorf("reading form: %w", err)
+	}
+
+	if _, err := time.Parse(internal.DateLayout, r.FormValue("firstDay")); err != nil {
+		return nil, fmt.Errorf("first day must be a date like 2022-06-07")
+	}
+
+	principal, err := intio.ParseAmount(r.FormValue("principal"))
+	if err != nil {
+		return nil, fmt.Errorf("principal must be an amount like 100000")
+	}
+
+	if len([]rune(r.FormValue("comma"))) != 1 {
+		return nil, fmt.Errorf("field delimiter must be a single character")
+	}
+
+	f := input.DefaultFlags()
+	f.FirstDay = r.FormValue("firstDay")
+	f.Principal = principal.RatString()
+	f.InComma = r.FormValue("comma")
+	f.OutComma = ","
+	if o := r.FormValue("overpayment"); o != "" {
+		f.Overpayment = o
+	}
+
+	for _, u := range []struct {
+		name     string
+		file     *string
+		optional bool
+	}{
+		{"transactions", &f.Transactions, false},
+		{"rates", &f.InterestRates, false},
+		{"kindRules", &f.KindRules, true},
+		{"fees", &f.Fees, true},
+		{"minimumPayments", &f.MinimumPayments, true},
+	} {
+		name, err := upload(r, u.name, u.optional)
+		if err != nil {
+			return nil, err
+		}
+		if name != "" {
+			defer os.Remove(name)
+		}
+		*u.file = name
+	}
+
+	in, err := f.Load()
+	if err != nil {
+		return nil, err
+	}
+	bank := in.NewBank()
+
+	var days bytes.Buffer
+	if err := calc.Run(r.Context(), &days, in.FirstDay, in.Principal, bank, in.OutComma); err != nil {
+		return nil, fmt.Errorf("calculating loan: %w", err)
+	}
+
+	var costs bytes.Buffer
+	if err := calc.RunCost(&costs, in.FirstDay, in.Principal, bank, in.OutComma); err != nil {
+		return nil, fmt.Errorf("calculating cost: %w", err)
+	}
+
+	res := &result{}
+	if res.Days, err = readTable(&days); err != nil {
+		return nil, err
+	}
+	if res.Costs, err = readTable(&costs); err != nil {
+		return nil, err
+	}
+	res.Chart = balanceChart(res.Days)
+
+	return res, nil
+}
+
+// upload saves the uploaded file of form field name to a temporary
+// file, which the caller must remove, and returns its name. An
+// optional file that was not uploaded has the empty name.
+func upload(r *http.Request, name string, optional bool) (string, error) {
+	file, _, err := r.FormFile(name)
+	if errors.Is(err, http.ErrMissingFile) && optional {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("missing %s file", name)
+	}
+	defer file.Close()
+
+	tmp, err := os.CreateTemp("", "7hlc-upload-*.csv")
+	if err != nil {
+		return "", fmt.Errorf("saving %s file: %w", name, err)
+	}
+
+	_, err = io.Copy(tmp, file)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return "", fmt.Errorf("saving %s file: %w", name, err)
+	}
+
+	return tmp.Name(), nil
+}
+
+// readTable reads CSV output written with "," as field delimiter.
+func readTable(r io.Reader) (table, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return table{}, fmt.Errorf("reading results: %w", err)
+	}
+	if len(records) == 0 {
+		return table{}, nil
+	}
+	return table{Header: records[0], Records: records[1:]}, nil
+}
+
+// chart is a line chart of the balance over the days of a calculation,
+// drawn as an SVG polyline.
+type chart struct {
+	Width, Height int
+	// Points are the points of the polyline, formatted for its points
+	// attribute. It is empty if there is nothing to draw.
+	Points string
+	// From and To are the first and last dates of the chart, and Min
+	// and Max the lowest and highest balances.
+	From, To string
+	Min, Max string
+}
+
+const (
+	chartWidth  = 800
+	chartHeight = 240
+)
+
+// balanceChart returns a chart of the balance column of days, the
+// output of [calc.Run].
+func balanceChart(days table) chart {
+	c := chart{Width: chartWidth, Height: chartHeight}
+
+	col := -1
+	for i, h := range days.Header {
+		if h == "Balance" {
+			col = i
+		}
+	}
+	if col < 0 || len(days.Records) == 0 {
+		return c
+	}
+
+	balances := make([]*big.Rat, len(days.Records))
+	for i, r := range days.Records {
+		b, ok := new(big.Rat).SetString(r[col])
+		if !ok {
+			return c
+		}
+		balances[i] = b
+	}
+
+	lo, hi := balances[0], balances[0]
+	for _, b := range balances {
+		if b.Cmp(lo) < 0 {
+			lo = b
+		}
+		if b.Cmp(hi) > 0 {
+			hi = b
+		}
+	}
+
+	// A flat line is drawn at the bottom, as the range is at least one
+	// to not divide by zero.
+	span, _ := new(big.Rat).Sub(hi, lo).Float64()
+	if span == 0 {
+		span = 1
+	}
+	low, _ := lo.Float64()
+
+	var points strings.Builder
+	for i, b := range balances {
+		x := 0.0
+		if len(balances) > 1 {
+			x = float64(i) * chartWidth / float64(len(balances)-1)
+		}
+		v, _ := b.Float64()
+		y := chartHeight - (v-low)*chartHeight/span
+		if i > 0 {
+			points.WriteByte(' ')
+		}
+		fmt.Fprintf(&points, "%.1f,%.1f", x, y)
+	}
+
+	c.Points = points.String()
+	c.From, c.To = days.Records[0][0], days.Records[len(days.Records)-1][0]
+	c.Min, c.Max = lo.FloatString(2), hi.FloatString(2)
+
+	return c
+}
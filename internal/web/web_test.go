@@ -0,0 +1,168 @@
+package web
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// post posts a calculation form with the given fields, and files read
+// from testdata, and returns the response body.
+func post(t *testing.T, fields map[string]string, files map[string]string) (int, string) {
+	t.Helper()
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	for name, value := range fields {
+		mw.WriteField(name, value)
+	}
+	for name, file := range files {
+		data, err := os.ReadFile(filepath.Join("..", "testdata", file))
+		if err != nil {
+			t.Fatalf("reading %s: %s", file, err)
+		}
+		fw, err := mw.CreateFormFile(name, file)
+		if err != nil {
+			t.Fatalf("creating form file: %s", err)
+		}
+		fw.Write(data)
+	}
+	mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+
+	rec := httptest.NewRecorder()
+	New().ServeHTTP(rec, req)
+
+	b, _ := io.ReadAll(rec.Body)
+	return rec.Code, string(b)
+}
+
+func TestIndex(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if want, got := http.StatusOK, rec.Code; want != got {
+		t.Fatalf("want status %d, but got %d", want, got)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, `enctype="multipart/form-data"`) {
+		t.Errorf("want an upload form, but got %s", body)
+	}
+}
+
+func TestStatic(t *testing.T) {
+	rec := httptest.NewRecorder()
+	New().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/static/style.css", nil))
+
+	if want, got := http.StatusOK, rec.Code; want != got {
+		t.Errorf("want status %d, but got %d", want, got)
+	}
+
+	rec = httptest.NewRecorder()
+	New().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/other", nil))
+
+	if want, got := http.StatusNotFound, rec.Code; want != got {
+		t.Errorf("want status %d, but got %d", want, got)
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	status, body := post(t,
+		map[string]string{"firstDay": "2022-06-07", "principal": "100000", "comma": ";"},
+		map[string]string{"transactions": "transactions.csv", "rates": "annual_interest_rates.csv"},
+	)
+
+	if want, got := http.StatusOK, status; want != got {
+		t.Fatalf("want status %d, but got %d", want, got)
+	}
+
+	for _, want := range []string{
+		"<td>2022-08-10</td><td>1.64</td><td>97202.14</td>",
+		"<th>Total cost</th>",
+		"<polyline points=",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("want %s in the result", want)
+		}
+	}
+	if strings.Contains(body, `class="error"`) {
+		t.Errorf("want no error, but got %s", body)
+	}
+}
+
+func TestCalculate_Inputs(t *testing.T) {
+	status, body := post(t,
+		map[string]string{"firstDay": "2022-06-07", "principal": "100000", "comma": ";", "overpayment": "credit"},
+		map[string]string{
+			"transactions":    "transactions.csv",
+			"rates":           "annual_interest_rates.csv",
+			"kindRules":       "kind_rules.csv",
+			"fees":            "fees.csv",
+			"minimumPayments": "payment_plan.csv",
+		},
+	)
+
+	if want, got := http.StatusOK, status; want != got {
+		t.Fatalf("want status %d, but got %d", want, got)
+	}
+	if strings.Contains(body, `class="error"`) {
+		t.Fatalf("want no error, but got %s", body)
+	}
+
+	for _, want := range []string{
+		"<th>Unpaid fees</th>",
+		"<th>Overdue</th>",
+		"<option selected>credit</option>",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("want %s in the result", want)
+		}
+	}
+}
+
+func TestCalculate_Error(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields map[string]string
+		files  map[string]string
+		want   string
+	}{
+		{
+			name:   "first day",
+			fields: map[string]string{"firstDay": "yesterday", "principal": "100000", "comma": ";"},
+			want:   "first day must be a date",
+		},
+		{
+			name:   "missing rates",
+			fields: map[string]string{"firstDay": "2022-06-07", "principal": "100000", "comma": ";"},
+			files:  map[string]string{"transactions": "transactions.csv"},
+			want:   "missing rates file",
+		},
+		{
+			name:   "overpayment policy",
+			fields: map[string]string{"firstDay": "2022-06-07", "principal": "100000", "comma": ";", "overpayment": "refund"},
+			files:  map[string]string{"transactions": "transactions.csv", "rates": "annual_interest_rates.csv"},
+			want:   "unknown overpayment policy &#34;refund&#34;",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := post(t, tt.fields, tt.files)
+
+			if want, got := http.StatusOK, status; want != got {
+				t.Fatalf("want status %d, but got %d", want, got)
+			}
+			if !strings.Contains(body, tt.want) {
+				t.Errorf("want error %q, but got %s", tt.want, body)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package loancalc_test
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/loancalc"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func Example() {
+	bank := loancalc.NewBank([]loancalc.Transaction{
+		loancalc.NewTransaction(date(2023, 1, 27), big.NewRat(2000, 1), loancalc.Payment),
+	}, []loancalc.AnnualInterestRate{
+		loancalc.NewAnnualInterestRate(date(2023, 1, 1), big.NewRat(3, 100)),
+	})
+
+	days, err := loancalc.Series(&bank, loancalc.NewLoan(big.NewRat(100000, 1)), date(2023, 1, 1), date(2023, 2, 1))
+	if err != nil {
+		log.Fatal(err)
+	}
+	last := days[len(days)-1]
+
+	fmt.Println(last.Date.Format("2006-01-02"))
+	fmt.Println(last.Loan.Balance().FloatString(2))
+	fmt.Println(last.Loan.Interest().FloatString(2))
+	// Output:
+	// 2023-02-01
+	// 98249.19
+	// 8.77
+}
+
+func ExampleBank_Process() {
+	bank := loancalc.NewBank(nil, []loancalc.AnnualInterestRate{
+		loancalc.NewAnnualInterestRate(date(2023, 1, 1), big.NewRat(372, 10000)),
+	})
+
+	loan, err := bank.Process(date(2023, 1, 2), loancalc.NewLoan(big.NewRat(100000, 1)))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(loan.Balance().FloatString(2), loan.Interest().FloatString(2))
+	// Output: 100000.00 10.00
+}
+
+func ExampleProject() {
+	bank := loancalc.NewBank(nil, []loancalc.AnnualInterestRate{
+		loancalc.NewAnnualInterestRate(date(2023, 1, 1), big.NewRat(0, 1)),
+	})
+	plan := []loancalc.PlannedPayment{
+		{From: date(2023, 1, 1), Day: 25, Amount: big.NewRat(1000, 1)},
+	}
+
+	days, _, err := loancalc.Project(bank, loancalc.NewLoan(big.NewRat(2500, 1)), date(2023, 1, 1), date(2030, 1, 1), plan)
+	if err != nil {
+		log.Fatal(err)
+	}
+	payoff := days[len(days)-1]
+
+	fmt.Println(payoff.Date.Format("2006-01-02"), payoff.PaidOff)
+	// Output: 2023-03-25 true
+}
+
+func ExampleRateNotFoundError() {
+	bank := loancalc.NewBank(nil, []loancalc.AnnualInterestRate{
+		loancalc.NewAnnualInterestRate(date(2023, 2, 1), big.NewRat(3, 100)),
+	})
+
+	_, err := loancalc.Series(&bank, loancalc.NewLoan(big.NewRat(1000, 1)), date(2023, 1, 1), date(2023, 2, 28))
+
+	var notFound *loancalc.RateNotFoundError
+	if errors.As(err, &notFound) {
+		fmt.Println("no rate on", notFound.Day.Format("2006-01-02"))
+	}
+	// Output: no rate on 2023-01-01
+}
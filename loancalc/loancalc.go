@@ -0,0 +1,166 @@
+// Package loancalc calculates the interest and balance of a loan day by
+// day from the transactions made and the interest rates in effect, with
+// exact arithmetic.
+//
+// A [Bank] holds the transactions and interest rates of a loan and
+// processes the [Loan] one day at a time (see [Bank.Process]). [Series]
+// processes a range of days, and [Project] also makes the payments of a
+// payment plan. Inputs can be built in code or read from the CSV files
+// used by the 7hlc command.
+package loancalc
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/calc"
+	"gitlab.joelpet.se/joelpet/7h-loan-calc/internal/io"
+)
+
+// Loan is the state of a loan at the end of a day. Use [NewLoan] to
+// create one and its accessors, e.g. [Loan.Balance], to read it.
+type Loan = calc.Loan
+
+// Bank processes a loan according to its transactions and interest
+// rates. Use [NewBank] to create one.
+type Bank = calc.Bank
+
+// Day is the state of a loan at the end of a calendar day along with
+// what happened during the day.
+type Day = calc.Day
+
+// Summary sums up a loan over a period of days.
+type Summary = calc.Summary
+
+// Due is a minimum payment that is due on a day.
+type Due = calc.Due
+
+// OverpaymentPolicy determines what happens to payments in excess of
+// what is owed on a loan.
+type OverpaymentPolicy = calc.OverpaymentPolicy
+
+// OverpaidError is an overpayment under the [OverpaymentError] policy.
+type OverpaidError = calc.OverpaidError
+
+// RateNotFoundError is returned when no interest rate covers a day
+// that a loan is processed on.
+type RateNotFoundError = calc.RateNotFoundError
+
+const (
+	OverpaymentSurplus = calc.OverpaymentSurplus
+	OverpaymentCredit  = calc.OverpaymentCredit
+	OverpaymentError   = calc.OverpaymentError
+)
+
+// Transaction is a transaction on the loan account.
+type Transaction = io.Transaction
+
+// Kind is the kind of a transaction, which determines how it affects
+// the loan.
+type Kind = io.Kind
+
+const (
+	Payment        = io.Payment
+	Disbursement   = io.Disbursement
+	Fee            = io.Fee
+	InterestCharge = io.InterestCharge
+	Refund         = io.Refund
+)
+
+// AnnualInterestRate is an annual interest rate, in decimal form, in
+// effect from a day on.
+type AnnualInterestRate = io.AnnualInterestRate
+
+// FixedRatePeriod is a period during which the interest rate is fixed.
+type FixedRatePeriod = io.FixedRatePeriod
+
+// ScheduledFee is a fee charged once or monthly.
+type ScheduledFee = io.ScheduledFee
+
+// PlannedPayment is an entry of a payment plan.
+type PlannedPayment = io.PlannedPayment
+
+// NewLoan returns a loan with the principal as its balance.
+func NewLoan(principal *big.Rat) Loan {
+	return calc.NewLoan(principal)
+}
+
+// NewBank returns a bank holding transactions and interest rates, which
+// are sorted by day.
+func NewBank(transactions []Transaction, interestRates []AnnualInterestRate) Bank {
+	return calc.NewBank(transactions, interestRates)
+}
+
+// NewTransaction returns a transaction of kind made on day.
+func NewTransaction(day time.Time, amount *big.Rat, kind Kind) Transaction {
+	return Transaction{
+		Date:        calc.DateFromTime(day),
+		Type:        kind.String(),
+		Description: kind.String(),
+		Amount:      new(big.Rat).Set(amount),
+		Currency:    "SEK",
+		Kind:        kind,
+	}
+}
+
+// NewAnnualInterestRate returns an annual interest rate, in decimal
+// form, in effect from day on.
+func NewAnnualInterestRate(day time.Time, decimalRate *big.Rat) AnnualInterestRate {
+	return AnnualInterestRate{Day: calc.DateFromTime(day), DecimalRate: new(big.Rat).Set(decimalRate)}
+}
+
+// Series processes loan with bank one day at a time, from the first
+// through the last day (both inclusive), and returns the state at the
+// end of each day. Processing stops at the first day that cannot be
+// processed (see [Bank.Process]), with an error.
+func Series(bank *Bank, loan Loan, first, last time.Time) ([]Day, error) {
+	return calc.Series(bank, loan, first, last)
+}
+
+// Walk is like [Series], but calls fn with the state at the end of
+// each day as soon as it has been processed, rather than collecting
+// the days. It stops at the first error, from processing a day or
+// returned by fn, or when ctx is done.
+func Walk(ctx context.Context, bank *Bank, loan Loan, first, last time.Time, fn func(Day) error) error {
+	return calc.Walk(ctx, bank, loan, first, last, fn)
+}
+
+// SeriesAt is like [Series], but returns the state at the end of the
+// given days, and of the last day, only. Days without transactions,
+// fees, rate changes, or capitalization are skipped over rather than
+// processed one at a time, which makes it much faster for long loans.
+func SeriesAt(bank *Bank, loan Loan, first, last time.Time, at []time.Time) ([]Day, error) {
+	return calc.SeriesAt(bank, loan, first, last, at)
+}
+
+// Project is like [Series], but also makes the payments of plan on
+// their due days, and ends on the day the loan is paid off. The
+// returned bank holds the planned payments in addition to the
+// transactions of bank.
+func Project(bank Bank, loan Loan, first, last time.Time, plan []PlannedPayment) ([]Day, Bank, error) {
+	return calc.Project(bank, loan, first, last, plan)
+}
+
+// Summarize sums up days processed by bank, which must not be empty.
+func Summarize(bank *Bank, days []Day) Summary {
+	return calc.Summarize(bank, days)
+}
+
+// ReadTransactions reads transactions from a CSV file exported from
+// the bank and classifies them by kind.
+func ReadTransactions(csvFilename string, comma rune) ([]Transaction, error) {
+	return io.ReadTransactions(csvFilename, comma)
+}
+
+// ReadInterestRates reads annual interest rates from a CSV file with a
+// header line followed by records of date and percentage.
+func ReadInterestRates(csvFilename string, comma rune) ([]AnnualInterestRate, error) {
+	return io.ReadInterestRates(csvFilename, comma)
+}
+
+// ReadPaymentPlan reads a payment plan from a CSV file with a header
+// line followed by records of first day, day of month, and amount.
+func ReadPaymentPlan(csvFilename string, comma rune) ([]PlannedPayment, error) {
+	return io.ReadPaymentPlan(csvFilename, comma)
+}